@@ -1,9 +1,71 @@
 package api
 
 import (
+	"errors"
+	"time"
+
 	"go.sia.tech/core/types"
+	"go.sia.tech/explorer"
+)
+
+// Error codes returned in an Error.
+const (
+	ErrorCodeBadRequest   = "bad_request"
+	ErrorCodeUnauthorized = "unauthorized"
+	ErrorCodeNotFound     = "not_found"
+	ErrorCodeInternal     = "internal"
+	ErrorCodeUnavailable  = "unavailable"
 )
 
+// Errors that an Error may wrap, for use with errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrInternal     = errors.New("internal server error")
+	ErrUnavailable  = errors.New("service unavailable")
+)
+
+// An Error is the body of every unsuccessful API response.
+type Error struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error corresponding to e's code, allowing
+// callers to test for e.g. ErrNotFound with errors.Is.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case ErrorCodeBadRequest:
+		return ErrBadRequest
+	case ErrorCodeUnauthorized:
+		return ErrUnauthorized
+	case ErrorCodeNotFound:
+		return ErrNotFound
+	case ErrorCodeInternal:
+		return ErrInternal
+	case ErrorCodeUnavailable:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// An ExplorerWebhookRequest registers a webhook that is notified of
+// transactions involving the given addresses and contracts. If Secret is
+// empty, the server generates one.
+type ExplorerWebhookRequest struct {
+	URL       string            `json:"url"`
+	Secret    string            `json:"secret,omitempty"`
+	Addresses []types.Address   `json:"addresses"`
+	Contracts []types.ElementID `json:"contracts"`
+}
+
 // TxpoolBroadcastRequest is the request for the /txpool/broadcast endpoint.
 // It contains the transaction to broadcast and the transactions that it
 // depends on.
@@ -22,6 +84,27 @@ type SyncerConnectRequest struct {
 	NetAddress string `json:"netAddress"`
 }
 
+// An ExplorerSiacoinElementResponse contains a siacoin element and, if it has
+// been spent, the transaction that spent it.
+type ExplorerSiacoinElementResponse struct {
+	SiacoinElement types.SiacoinElement `json:"siacoinElement"`
+	Spent          bool                 `json:"spent"`
+	SpentBy        types.TransactionID  `json:"spentBy"`
+	SpentHeight    uint64               `json:"spentHeight"`
+}
+
+// An ExplorerSiafundElementResponse contains a siafund element and, if it has
+// been spent, the transaction that spent it. Claim is the siafund pool revenue
+// claimed when the element was spent or, if it is unspent, the revenue it has
+// accrued so far.
+type ExplorerSiafundElementResponse struct {
+	SiafundElement types.SiafundElement `json:"siafundElement"`
+	Spent          bool                 `json:"spent"`
+	SpentBy        types.TransactionID  `json:"spentBy"`
+	SpentHeight    uint64               `json:"spentHeight"`
+	Claim          types.Currency       `json:"claim"`
+}
+
 // A ExplorerSearchResponse contains information about an element.
 type ExplorerSearchResponse struct {
 	Type                string                    `json:"type"`
@@ -30,17 +113,148 @@ type ExplorerSearchResponse struct {
 	FileContractElement types.FileContractElement `json:"fileContractElement"`
 }
 
+// Search result types.
+const (
+	SearchResultBlock          = "block"
+	SearchResultTransaction    = "transaction"
+	SearchResultSiacoinElement = "siacoinElement"
+	SearchResultSiafundElement = "siafundElement"
+	SearchResultContract       = "contract"
+	SearchResultAddress        = "address"
+	SearchResultHost           = "host"
+)
+
+// An ExplorerSearchResult is an object matching a search query. ID is the
+// canonical string form of the object's identifier, e.g. a block ID for a
+// block found by its height.
+type ExplorerSearchResult struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
 // A ExplorerWalletBalanceResponse contains the confirmed Siacoin and Siafund balance of
-// the wallet.
+// the wallet. When requested for a single address, it also contains the
+// address's rank in the rich list of each currency and the corresponding
+// percentile; a rank of zero means the address holds none of that currency.
 type ExplorerWalletBalanceResponse struct {
 	Siacoins types.Currency `json:"siacoins"`
 	Siafunds uint64         `json:"siafunds"`
+
+	SiacoinRank       uint64  `json:"siacoinRank,omitempty"`
+	SiacoinPercentile float64 `json:"siacoinPercentile,omitempty"`
+	SiafundRank       uint64  `json:"siafundRank,omitempty"`
+	SiafundPercentile float64 `json:"siafundPercentile,omitempty"`
+}
+
+// An ExplorerTransactionResponse contains a confirmed transaction, the block it
+// was confirmed in, and the number of blocks that have been added on top of it
+// (including its own).
+type ExplorerTransactionResponse struct {
+	Transaction   types.Transaction `json:"transaction"`
+	Height        uint64            `json:"height"`
+	BlockID       types.BlockID     `json:"blockID"`
+	Index         int               `json:"index"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Confirmations uint64            `json:"confirmations"`
+}
+
+// An ExplorerAddressPageRequest requests a page of a list associated with an
+// address, such as its transactions or unspent elements. A zero Limit
+// requests the default page size.
+type ExplorerAddressPageRequest struct {
+	Address types.Address   `json:"address"`
+	Cursor  explorer.Cursor `json:"cursor"`
+	Limit   int             `json:"limit"`
+}
+
+// An ExplorerBatchSiacoinsResponse contains a page of the unspent siacoin
+// elements of an address, newest first. NextCursor is empty on the last page.
+type ExplorerBatchSiacoinsResponse struct {
+	Elements   []types.SiacoinElement `json:"elements"`
+	NextCursor explorer.Cursor        `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerBatchSiafundsResponse contains a page of the unspent siafund
+// elements of an address, newest first. NextCursor is empty on the last page.
+type ExplorerBatchSiafundsResponse struct {
+	Elements   []types.SiafundElement `json:"elements"`
+	NextCursor explorer.Cursor        `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerBatchTransactionsResponse contains a page of the transactions
+// involving an address, newest first. NextCursor is empty on the last page.
+type ExplorerBatchTransactionsResponse struct {
+	Transactions []types.Transaction `json:"transactions"`
+	NextCursor   explorer.Cursor     `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerTransactionsResponse contains a page of transaction IDs, newest
+// first. NextCursor is empty on the last page.
+type ExplorerTransactionsResponse struct {
+	IDs        []types.TransactionID `json:"ids"`
+	NextCursor explorer.Cursor       `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerElementsResponse contains a page of element IDs, newest first.
+// NextCursor is empty on the last page.
+type ExplorerElementsResponse struct {
+	IDs        []types.ElementID `json:"ids"`
+	NextCursor explorer.Cursor   `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerAttestationsResponse contains a page of attestations, newest
+// first. NextCursor is empty on the last page.
+type ExplorerAttestationsResponse struct {
+	Attestations []explorer.Attestation `json:"attestations"`
+	NextCursor   explorer.Cursor        `json:"nextCursor,omitempty"`
 }
 
-// A ExplorerTransactionsRequest contains an address and the amount of
-// transactions involving the address to request.
-type ExplorerTransactionsRequest struct {
+// An ExplorerMinerBlocksResponse contains a page of the payouts of blocks mined
+// by an address, newest first. NextCursor is empty on the last page.
+type ExplorerMinerBlocksResponse struct {
+	Blocks     []explorer.MinerPayout `json:"blocks"`
+	NextCursor explorer.Cursor        `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerRichListResponse contains a page of a rich list, largest balance
+// first. NextCursor is empty on the last page.
+type ExplorerRichListResponse struct {
+	Balances   []explorer.RankedBalance `json:"balances"`
+	NextCursor explorer.Cursor          `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerSiafundClaimsResponse contains a page of the siafund claims made
+// by an address, newest first. NextCursor is empty on the last page.
+type ExplorerSiafundClaimsResponse struct {
+	Claims     []explorer.SiafundClaim `json:"claims"`
+	NextCursor explorer.Cursor         `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerFoundationResponse contains the Foundation address as of a
+// particular height.
+type ExplorerFoundationResponse struct {
+	Height  uint64        `json:"height"`
 	Address types.Address `json:"address"`
-	Amount  int           `json:"amount"`
-	Offset  int           `json:"offset"`
+}
+
+// An ExplorerFoundationChangesResponse contains a page of changes to the
+// Foundation address, newest first. NextCursor is empty on the last page.
+type ExplorerFoundationChangesResponse struct {
+	Changes    []explorer.FoundationChange `json:"changes"`
+	NextCursor explorer.Cursor             `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerFoundationSubsidiesResponse contains a page of subsidies paid to
+// the Foundation, newest first. NextCursor is empty on the last page.
+type ExplorerFoundationSubsidiesResponse struct {
+	Subsidies  []explorer.FoundationSubsidy `json:"subsidies"`
+	NextCursor explorer.Cursor              `json:"nextCursor,omitempty"`
+}
+
+// An ExplorerHostResponse contains aggregate information about the contracts
+// formed with a host. ProofSuccessRate is the fraction of the storage proofs
+// due from the host that it submitted.
+type ExplorerHostResponse struct {
+	Stats            explorer.HostStats `json:"stats"`
+	ProofSuccessRate float64            `json:"proofSuccessRate"`
 }
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"go.sia.tech/core/consensus"
 	"go.sia.tech/core/types"
@@ -39,8 +42,7 @@ func (c *Client) req(method string, route string, data, resp interface{}) error
 	defer io.Copy(ioutil.Discard, r.Body)
 	defer r.Body.Close()
 	if r.StatusCode != 200 {
-		err, _ := ioutil.ReadAll(r.Body)
-		return errors.New(string(err))
+		return decodeError(r)
 	}
 	if resp == nil {
 		return nil
@@ -48,11 +50,73 @@ func (c *Client) req(method string, route string, data, resp interface{}) error
 	return json.NewDecoder(r.Body).Decode(resp)
 }
 
+// decodeError decodes the Error in an unsuccessful response. If the body is
+// not an Error, e.g. because it was written by a proxy, an Error is derived
+// from the status code instead.
+func decodeError(r *http.Response) error {
+	body, _ := ioutil.ReadAll(r.Body)
+	var apiErr Error
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Code != "" {
+		return &apiErr
+	}
+	apiErr.Message = strings.TrimSpace(string(body))
+	switch r.StatusCode {
+	case http.StatusBadRequest:
+		apiErr.Code = ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		apiErr.Code = ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		apiErr.Code = ErrorCodeNotFound
+	case http.StatusServiceUnavailable:
+		apiErr.Code = ErrorCodeUnavailable
+	default:
+		apiErr.Code = ErrorCodeInternal
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(r.StatusCode)
+	}
+	return &apiErr
+}
+
 func (c *Client) get(route string, r interface{}) error     { return c.req("GET", route, nil, r) }
 func (c *Client) post(route string, d, r interface{}) error { return c.req("POST", route, d, r) }
 func (c *Client) put(route string, d interface{}) error     { return c.req("PUT", route, d, nil) }
 func (c *Client) delete(route string) error                 { return c.req("DELETE", route, nil, nil) }
 
+// formatChainIndex returns the full text form of index. Unlike String, which
+// abbreviates the block ID, the result can be parsed by the server.
+func formatChainIndex(index types.ChainIndex) string {
+	text, _ := index.MarshalText()
+	return string(text)
+}
+
+// pageValues returns the query parameters of a paginated request.
+func pageValues(cursor explorer.Cursor, limit int) url.Values {
+	v := url.Values{}
+	if cursor != "" {
+		v.Set("cursor", string(cursor))
+	}
+	if limit != 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	return v
+}
+
+// pageQuery encodes the query parameters of a paginated request.
+func pageQuery(cursor explorer.Cursor, limit int) string {
+	return pageValues(cursor, limit).Encode()
+}
+
+// contractsQuery encodes the query parameters of a paginated request for
+// contracts with the given status.
+func contractsQuery(status string, cursor explorer.Cursor, limit int) string {
+	v := pageValues(cursor, limit)
+	if status != "" {
+		v.Set("status", status)
+	}
+	return v.Encode()
+}
+
 // WriteJSON writes the JSON encoded object to the http response.
 func WriteJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -62,134 +126,421 @@ func WriteJSON(w http.ResponseWriter, v interface{}) {
 
 // TxpoolBroadcast broadcasts a transaction to the network.
 func (c *Client) TxpoolBroadcast(txn types.Transaction, dependsOn []types.Transaction) (err error) {
-	err = c.post("/api/txpool/broadcast", TxpoolBroadcastRequest{dependsOn, txn}, nil)
+	err = c.post("/api/v1/txpool/broadcast", TxpoolBroadcastRequest{dependsOn, txn}, nil)
 	return
 }
 
 // TxpoolTransactions returns all transactions in the transaction pool.
-func (c *Client) TxpoolTransactions() (resp []types.Transaction, err error) {
-	err = c.get("/api/txpool/transactions", &resp)
+func (c *Client) TxpoolTransactions() (resp []explorer.UnconfirmedTransaction, err error) {
+	err = c.get("/api/v1/txpool/transactions", &resp)
+	return
+}
+
+// TxpoolFeeEstimate returns suggested fee rates for new transactions.
+func (c *Client) TxpoolFeeEstimate() (resp explorer.FeeEstimate, err error) {
+	err = c.get("/api/v1/txpool/fee-estimate", &resp)
 	return
 }
 
 // SyncerPeers returns the current peers of the syncer.
 func (c *Client) SyncerPeers() (resp []SyncerPeerResponse, err error) {
-	err = c.get("/api/syncer/peers", &resp)
+	err = c.get("/api/v1/syncer/peers", &resp)
 	return
 }
 
 // SyncerConnect adds the address as a peer of the syncer.
 func (c *Client) SyncerConnect(addr string) (err error) {
-	err = c.post("/api/syncer/connect", addr, nil)
+	err = c.post("/api/v1/syncer/connect", SyncerConnectRequest{addr}, nil)
 	return
 }
 
 // ChainStats returns stats about the chain at the given index.
 func (c *Client) ChainStats(index types.ChainIndex) (resp explorer.ChainStats, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/chain/%s", index.String()), &resp)
+	err = c.get(fmt.Sprintf("/api/v1/explorer/chain/%s", formatChainIndex(index)), &resp)
 	return
 }
 
 // ChainState returns the validation context at a given chain index.
 func (c *Client) ChainState(index types.ChainIndex) (resp consensus.State, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/chain/%s/state", index.String()), &resp)
+	err = c.get(fmt.Sprintf("/api/v1/explorer/chain/%s/state", formatChainIndex(index)), &resp)
+	return
+}
+
+// Search returns the objects matching query, which may be a block height or
+// the string form of a block ID, transaction ID, element ID, address, or host
+// public key.
+func (c *Client) Search(query string) (resp []ExplorerSearchResult, err error) {
+	err = c.get("/api/v1/explorer/search/"+url.PathEscape(query), &resp)
+	return
+}
+
+// BlockByHeight returns the block at the given height in the best chain.
+func (c *Client) BlockByHeight(height uint64) (resp types.Block, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/block/%d", height), &resp)
+	return
+}
+
+// BlockByID returns the block with the given ID.
+func (c *Client) BlockByID(id types.BlockID) (resp types.Block, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/block/%s", id.String()), &resp)
 	return
 }
 
-// SiacoinElement returns the Siacoin element with the given ID.
-func (c *Client) SiacoinElement(id types.ElementID) (resp types.SiacoinElement, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/element/siacoin/%s", id.String()), &resp)
+// SiacoinElement returns the Siacoin element with the given ID and its spend
+// status.
+func (c *Client) SiacoinElement(id types.ElementID) (resp ExplorerSiacoinElementResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/element/siacoin/%s", id.String()), &resp)
 	return
 }
 
-// SiafundElement returns the Siafund element with the given ID.
-func (c *Client) SiafundElement(id types.ElementID) (resp types.SiafundElement, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/element/siafund/%s", id.String()), &resp)
+// SiafundElement returns the Siafund element with the given ID and its spend
+// status.
+func (c *Client) SiafundElement(id types.ElementID) (resp ExplorerSiafundElementResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/element/siafund/%s", id.String()), &resp)
 	return
 }
 
 // FileContractElement returns the file contract element with the given ID.
 func (c *Client) FileContractElement(id types.ElementID) (resp types.FileContractElement, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/element/contract/%s", id.String()), &resp)
+	err = c.get(fmt.Sprintf("/api/v1/explorer/element/contract/%s", id.String()), &resp)
+	return
+}
+
+// ContractHistory returns the lifecycle of a file contract: its formation,
+// each revision, and its resolution, oldest first.
+func (c *Client) ContractHistory(id types.ElementID) (resp []explorer.ContractEvent, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/contract/%s/history", id.String()), &resp)
+	return
+}
+
+// ContractsExpiring returns the active contracts whose proof window opens or
+// closes at a height in [from, to].
+func (c *Client) ContractsExpiring(from, to uint64) (resp []types.FileContractElement, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/contracts/expiring?from=%d&to=%d", from, to), &resp)
+	return
+}
+
+// Attestations returns a page of the attestations made by a public key, newest
+// first. If key is non-empty, only attestations with that key are returned.
+func (c *Client) Attestations(pubkey types.PublicKey, key string, cursor explorer.Cursor, limit int) (resp ExplorerAttestationsResponse, err error) {
+	v := pageValues(cursor, limit)
+	if key != "" {
+		v.Set("key", key)
+	}
+	err = c.get(fmt.Sprintf("/api/v1/explorer/attestations/%s?%s", pubkey.String(), v.Encode()), &resp)
+	return
+}
+
+// LatestAttestations returns the most recent attestation made by a public key
+// for each key.
+func (c *Client) LatestAttestations(pubkey types.PublicKey) (resp []explorer.Attestation, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/attestations/%s/latest", pubkey.String()), &resp)
+	return
+}
+
+// ArbitraryDataTransactions returns a page of the IDs of transactions whose
+// arbitrary data begins with prefix, newest first.
+func (c *Client) ArbitraryDataTransactions(prefix []byte, cursor explorer.Cursor, limit int) (resp ExplorerTransactionsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/arbitrary/%x?%s", prefix, pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// MinerBlocks returns a page of the payouts of blocks mined by an address,
+// newest first.
+func (c *Client) MinerBlocks(address types.Address, cursor explorer.Cursor, limit int) (resp ExplorerMinerBlocksResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/miner/%s/blocks?%s", address.String(), pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// MinerShares returns the share of blocks mined by each address at heights in
+// [start, end].
+func (c *Client) MinerShares(start, end uint64) (resp []explorer.MinerShare, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/miners?start=%d&end=%d", start, end), &resp)
+	return
+}
+
+// SupplyStats returns the supply statistics of every step'th block at heights
+// in [start, end].
+func (c *Client) SupplyStats(start, end, step uint64) (resp []explorer.SupplyStats, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/stats/supply?start=%d&end=%d&step=%d", start, end, step), &resp)
+	return
+}
+
+// NetworkMetrics returns the network metrics over the blocks at heights in
+// [start, end].
+func (c *Client) NetworkMetrics(start, end uint64) (resp explorer.NetworkMetrics, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/metrics?start=%d&end=%d", start, end), &resp)
+	return
+}
+
+// BlockMetrics returns the network metrics of each block at heights in
+// [start, end].
+func (c *Client) BlockMetrics(start, end uint64) (resp []explorer.BlockMetrics, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/metrics/blocks?start=%d&end=%d", start, end), &resp)
+	return
+}
+
+// FeeStats returns the fee stats of each block at heights in [start, end].
+func (c *Client) FeeStats(start, end uint64) (resp []explorer.FeeStats, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/fees?start=%d&end=%d", start, end), &resp)
+	return
+}
+
+// SiafundPoolHistory returns the value and growth of the siafund pool after
+// each block at heights in [start, end].
+func (c *Client) SiafundPoolHistory(start, end uint64) (resp []explorer.SiafundPoolGrowth, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/siafunds/pool?start=%d&end=%d", start, end), &resp)
+	return
+}
+
+// FoundationAddress returns the Foundation address as of the block at the
+// specified height.
+func (c *Client) FoundationAddress(height uint64) (resp ExplorerFoundationResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/foundation?height=%d", height), &resp)
+	return
+}
+
+// FoundationChanges returns a page of changes to the Foundation address,
+// newest first.
+func (c *Client) FoundationChanges(cursor explorer.Cursor, limit int) (resp ExplorerFoundationChangesResponse, err error) {
+	err = c.get("/api/v1/explorer/foundation/changes?"+pageQuery(cursor, limit), &resp)
+	return
+}
+
+// FoundationSubsidies returns a page of subsidies paid to the Foundation,
+// newest first.
+func (c *Client) FoundationSubsidies(cursor explorer.Cursor, limit int) (resp ExplorerFoundationSubsidiesResponse, err error) {
+	err = c.get("/api/v1/explorer/foundation/subsidies?"+pageQuery(cursor, limit), &resp)
+	return
+}
+
+// HostContracts returns a page of the IDs of contracts formed with a host,
+// newest first. If status is non-empty, only contracts with that status are
+// returned.
+func (c *Client) HostContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) (resp ExplorerElementsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/host/%s/contracts?%s", pubkey.String(), contractsQuery(status, cursor, limit)), &resp)
+	return
+}
+
+// RenterContracts returns a page of the IDs of contracts formed by a renter,
+// newest first. If status is non-empty, only contracts with that status are
+// returned.
+func (c *Client) RenterContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) (resp ExplorerElementsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/renter/%s/contracts?%s", pubkey.String(), contractsQuery(status, cursor, limit)), &resp)
+	return
+}
+
+// HostStats returns aggregate information about the contracts formed with a
+// host.
+func (c *Client) HostStats(pubkey types.PublicKey) (resp ExplorerHostResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/host/%s", pubkey.String()), &resp)
+	return
+}
+
+// ElementTrace walks the transaction graph starting at the given element. If
+// forward is true, it follows outputs to the transactions that spent them;
+// otherwise, it follows inputs back to the transactions that created them.
+func (c *Client) ElementTrace(id types.ElementID, forward bool, depth int) (resp []explorer.TraceStep, err error) {
+	direction := "backward"
+	if forward {
+		direction = "forward"
+	}
+	err = c.get(fmt.Sprintf("/api/v1/explorer/element/trace/%s?direction=%s&depth=%d", id.String(), direction, depth), &resp)
 	return
 }
 
 // ElementSearch returns information about a given element.
 func (c *Client) ElementSearch(id types.ElementID) (resp ExplorerSearchResponse, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/element/search/%s", id.String()), &resp)
+	err = c.get(fmt.Sprintf("/api/v1/explorer/element/search/%s", id.String()), &resp)
 	return
 }
 
-// AddressBalance returns the siacoin and siafund balance of an address.
+// AddressBalance returns the siacoin and siafund balance of an address, and its
+// rank in the rich list of each currency.
 func (c *Client) AddressBalance(address types.Address) (resp ExplorerWalletBalanceResponse, err error) {
-	data, err := json.Marshal(address)
-	if err != nil {
-		return
-	}
-	err = c.get(fmt.Sprintf("/api/explorer/address/%s/balance", string(data)), &resp)
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/balance", address.String()), &resp)
 	return
 }
 
-// SiacoinOutputs returns the unspent siacoin elements of an address.
-func (c *Client) SiacoinOutputs(address types.Address) (resp []types.ElementID, err error) {
-	data, err := json.Marshal(address)
-	if err != nil {
-		return
-	}
-	err = c.get(fmt.Sprintf("/api/explorer/address/%s/siacoins", string(data)), &resp)
+// SiacoinRichList returns a page of addresses ranked by siacoin balance.
+func (c *Client) SiacoinRichList(cursor explorer.Cursor, limit int) (resp ExplorerRichListResponse, err error) {
+	err = c.get("/api/v1/explorer/richlist/siacoins?"+pageQuery(cursor, limit), &resp)
 	return
 }
 
-// SiafundOutputs returns the unspent siafunds elements of an address.
-func (c *Client) SiafundOutputs(address types.Address) (resp []types.ElementID, err error) {
-	data, err := json.Marshal(address)
-	if err != nil {
-		return
-	}
-	err = c.get(fmt.Sprintf("/api/explorer/address/%s/siafunds", string(data)), &resp)
+// SiafundRichList returns a page of addresses ranked by siafund balance.
+func (c *Client) SiafundRichList(cursor explorer.Cursor, limit int) (resp ExplorerRichListResponse, err error) {
+	err = c.get("/api/v1/explorer/richlist/siafunds?"+pageQuery(cursor, limit), &resp)
 	return
 }
 
-// Transactions returns the latest transaction IDs the address was involved in.
-func (c *Client) Transactions(address types.Address, amount, offset int) (resp []types.TransactionID, err error) {
-	data, err := json.Marshal(address)
-	if err != nil {
-		return
-	}
-	err = c.get(fmt.Sprintf("/api/explorer/address/%s/transactions?amount=%d&offset=%d", string(data), amount, offset), &resp)
+// AddressBalanceHistory returns the balance of an address at each height in
+// [start, end] where a block affected it.
+func (c *Client) AddressBalanceHistory(address types.Address, start, end uint64) (resp []explorer.HistoricalBalance, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/history?start=%d&end=%d", address.String(), start, end), &resp)
+	return
+}
+
+// SiacoinOutputs returns a page of the unspent siacoin elements of an address,
+// newest first. An empty cursor requests the first page, and a zero limit
+// requests the default page size.
+func (c *Client) SiacoinOutputs(address types.Address, cursor explorer.Cursor, limit int) (resp ExplorerElementsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/siacoins?%s", address.String(), pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// SiafundOutputs returns a page of the unspent siafund elements of an address,
+// newest first. An empty cursor requests the first page, and a zero limit
+// requests the default page size.
+func (c *Client) SiafundOutputs(address types.Address, cursor explorer.Cursor, limit int) (resp ExplorerElementsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/siafunds?%s", address.String(), pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// SiafundClaims returns a page of the siafund claims made by an address, newest
+// first.
+func (c *Client) SiafundClaims(address types.Address, cursor explorer.Cursor, limit int) (resp ExplorerSiafundClaimsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/claims?%s", address.String(), pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// SiafundRevenue returns the siafund pool revenue accrued and claimed by an
+// address.
+func (c *Client) SiafundRevenue(address types.Address) (resp explorer.SiafundRevenue, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/revenue", address.String()), &resp)
 	return
 }
 
-// Transaction returns a transaction with the given ID.
-func (c *Client) Transaction(id types.TransactionID) (resp types.Transaction, err error) {
-	err = c.get(fmt.Sprintf("/api/explorer/transaction/%s", id.String()), &resp)
+// PendingActivity returns the unconfirmed transactions involving an address,
+// and the value they would send to and from it.
+func (c *Client) PendingActivity(address types.Address) (resp explorer.PendingActivity, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/pending", address.String()), &resp)
+	return
+}
+
+// Transactions returns a page of the IDs of transactions the address was
+// involved in, newest first. An empty cursor requests the first page, and a
+// zero limit requests the default page size.
+func (c *Client) Transactions(address types.Address, cursor explorer.Cursor, limit int) (resp ExplorerTransactionsResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/address/%s/transactions?%s", address.String(), pageQuery(cursor, limit)), &resp)
+	return
+}
+
+// Transaction returns a transaction with the given ID, along with the block it
+// was confirmed in and its current number of confirmations.
+func (c *Client) Transaction(id types.TransactionID) (resp ExplorerTransactionResponse, err error) {
+	err = c.get(fmt.Sprintf("/api/v1/explorer/transaction/%s", id.String()), &resp)
 	return
 }
 
 // BatchBalance returns the siacoin and siafund balance of a list of addresses.
 func (c *Client) BatchBalance(addresses []types.Address) (resp []ExplorerWalletBalanceResponse, err error) {
-	err = c.post("/api/explorer/batch/addresses/balance", addresses, &resp)
+	err = c.post("/api/v1/explorer/batch/addresses/balance", addresses, &resp)
+	return
+}
+
+// BatchSiacoins returns a page of the unspent siacoin elements of each of the
+// requested addresses.
+func (c *Client) BatchSiacoins(reqs []ExplorerAddressPageRequest) (resp []ExplorerBatchSiacoinsResponse, err error) {
+	err = c.post("/api/v1/explorer/batch/addresses/siacoins", reqs, &resp)
 	return
 }
 
-// BatchSiacoins returns the unspent siacoin elements of the addresses.
-func (c *Client) BatchSiacoins(addresses []types.Address) (resp [][]types.SiacoinElement, err error) {
-	err = c.post("/api/explorer/batch/addresses/siacoins", addresses, &resp)
+// BatchSiafunds returns a page of the unspent siafund elements of each of the
+// requested addresses.
+func (c *Client) BatchSiafunds(reqs []ExplorerAddressPageRequest) (resp []ExplorerBatchSiafundsResponse, err error) {
+	err = c.post("/api/v1/explorer/batch/addresses/siafunds", reqs, &resp)
 	return
 }
 
-// BatchSiafunds returns the unspent siafund elements of the addresses.
-func (c *Client) BatchSiafunds(addresses []types.Address) (resp [][]types.SiafundElement, err error) {
-	err = c.post("/api/explorer/batch/addresses/siafunds", addresses, &resp)
+// BatchTransactions returns a page of the transactions of each of the
+// requested addresses.
+func (c *Client) BatchTransactions(reqs []ExplorerAddressPageRequest) (resp []ExplorerBatchTransactionsResponse, err error) {
+	err = c.post("/api/v1/explorer/batch/addresses/transactions", reqs, &resp)
 	return
 }
 
-// BatchTransactions returns the last n transactions of the addresses.
-func (c *Client) BatchTransactions(addresses []ExplorerTransactionsRequest) (resp [][]types.Transaction, err error) {
-	err = c.post("/api/explorer/batch/addresses/transactions", addresses, &resp)
+// AddWebhook registers a webhook. The returned webhook includes its ID and
+// the secret used to sign its deliveries.
+func (c *Client) AddWebhook(req ExplorerWebhookRequest) (resp explorer.Webhook, err error) {
+	err = c.post("/api/v1/explorer/webhooks", req, &resp)
 	return
 }
 
+// Webhooks returns the registered webhooks, without their secrets.
+func (c *Client) Webhooks() (resp []explorer.Webhook, err error) {
+	err = c.get("/api/v1/explorer/webhooks", &resp)
+	return
+}
+
+// RemoveWebhook unregisters a webhook.
+func (c *Client) RemoveWebhook(id int64) (err error) {
+	err = c.delete(fmt.Sprintf("/api/v1/explorer/webhooks/%d", id))
+	return
+}
+
+// An EventStream reads events from the server's event stream.
+type EventStream struct {
+	body io.ReadCloser
+	r    *bufio.Reader
+}
+
+// Next blocks until the next event arrives.
+func (es *EventStream) Next() (ev explorer.Event, err error) {
+	var data []byte
+	for {
+		line, err := es.r.ReadBytes('\n')
+		if err != nil {
+			return explorer.Event{}, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 && data != nil {
+			break
+		} else if bytes.HasPrefix(line, []byte("data: ")) {
+			data = append(data, bytes.TrimPrefix(line, []byte("data: "))...)
+		}
+	}
+	err = json.Unmarshal(data, &ev)
+	return
+}
+
+// Close closes the stream.
+func (es *EventStream) Close() error {
+	return es.body.Close()
+}
+
+// Events opens a stream of the events published by the explorer and its
+// transaction pool. If addresses or elements are non-empty, only transactions
+// involving them are included; block events are always included. If since is
+// non-nil, the stream begins by replaying the blocks after since, preceded by
+// the reversion of any blocks that were reorged out since then.
+func (c *Client) Events(since *types.ChainIndex, addresses []types.Address, elements []types.ElementID) (*EventStream, error) {
+	v := url.Values{}
+	if since != nil {
+		v.Set("since", formatChainIndex(*since))
+	}
+	for _, addr := range addresses {
+		v.Add("address", addr.String())
+	}
+	for _, id := range elements {
+		v.Add("element", id.String())
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%v/api/v1/explorer/events?%v", c.BaseURL, v.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.SetBasicAuth("", c.AuthPassword)
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if r.StatusCode != 200 {
+		defer r.Body.Close()
+		return nil, decodeError(r)
+	}
+	return &EventStream{body: r.Body, r: bufio.NewReader(r.Body)}, nil
+}
+
 // NewClient returns a client that communicates with a explorerd server
 // listening on the specified address.
 func NewClient(addr, password string) *Client {
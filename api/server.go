@@ -1,9 +1,16 @@
 package api
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"go.sia.tech/core/consensus"
@@ -20,15 +27,29 @@ type (
 		BroadcastTransaction(txn types.Transaction, dependsOn []types.Transaction)
 	}
 
-	// A TransactionPool can validate and relay unconfirmed transactions.
-	TransactionPool interface {
-		Transactions() []types.Transaction
-		AddTransaction(txn types.Transaction) error
-	}
-
 	// A ChainManager manages blockchain state.
 	ChainManager interface {
 		TipState() consensus.State
+		Block(index types.ChainIndex) (types.Block, error)
+		State(index types.ChainIndex) (consensus.State, error)
+	}
+
+	// A Mempool tracks the unconfirmed transactions in a transaction pool,
+	// publishing an event for each one added.
+	Mempool interface {
+		AddTransaction(txn types.Transaction) error
+		Transactions() []explorer.UnconfirmedTransaction
+		PendingActivity(address types.Address) explorer.PendingActivity
+		AddEventSubscriber(s explorer.EventSubscriber)
+		RemoveEventSubscriber(s explorer.EventSubscriber)
+	}
+
+	// A WebhookManager delivers notifications about watched addresses and
+	// contracts.
+	WebhookManager interface {
+		AddWebhook(wh explorer.Webhook) (explorer.Webhook, error)
+		RemoveWebhook(id int64) error
+		Webhooks() []explorer.Webhook
 	}
 
 	// An Explorer contains a database storing information about blocks, outputs,
@@ -37,46 +58,123 @@ type (
 		SiacoinElement(id types.ElementID) (types.SiacoinElement, error)
 		SiafundElement(id types.ElementID) (types.SiafundElement, error)
 		FileContractElement(id types.ElementID) (types.FileContractElement, error)
+		ElementStatus(id types.ElementID) (explorer.ElementStatus, error)
+		Trace(id types.ElementID, forward bool, maxDepth int) ([]explorer.TraceStep, error)
 		ChainStats(index types.ChainIndex) (explorer.ChainStats, error)
 		ChainStatsLatest() (explorer.ChainStats, error)
-		SiacoinBalance(address types.Address) (types.Currency, error)
-		SiafundBalance(address types.Address) (uint64, error)
+		SupplyStats(start, end, step uint64) ([]explorer.SupplyStats, error)
+		BlockMetrics(start, end uint64) ([]explorer.BlockMetrics, error)
+		NetworkMetrics(start, end uint64) (explorer.NetworkMetrics, error)
+		FeeStats(start, end uint64) ([]explorer.FeeStats, error)
+		SiafundClaim(id types.ElementID) (explorer.SiafundClaim, error)
+		SiafundClaims(address types.Address, cursor explorer.Cursor, limit int) ([]explorer.SiafundClaim, explorer.Cursor, error)
+		AccruedClaim(sfe types.SiafundElement) types.Currency
+		SiafundRevenue(address types.Address) (explorer.SiafundRevenue, error)
+		SiafundPoolHistory(start, end uint64) ([]explorer.SiafundPoolGrowth, error)
+		FoundationAddress(height uint64) (types.Address, error)
+		FoundationChanges(cursor explorer.Cursor, limit int) ([]explorer.FoundationChange, explorer.Cursor, error)
+		FoundationSubsidies(cursor explorer.Cursor, limit int) ([]explorer.FoundationSubsidy, explorer.Cursor, error)
+		BlockByHeight(height uint64) (types.Block, error)
+		BlockByID(id types.BlockID) (types.Block, error)
+		AddressBalance(address types.Address) (sc types.Currency, sf uint64, err error)
+		AddressRank(address types.Address) (explorer.AddressRank, error)
+		SiacoinRichList(cursor explorer.Cursor, limit int) ([]explorer.RankedBalance, explorer.Cursor, error)
+		SiafundRichList(cursor explorer.Cursor, limit int) ([]explorer.RankedBalance, explorer.Cursor, error)
 		Transaction(id types.TransactionID) (types.Transaction, error)
-		UnspentSiacoinElements(address types.Address) ([]types.ElementID, error)
-		UnspentSiafundElements(address types.Address) ([]types.ElementID, error)
-		Transactions(address types.Address, amount, offset int) ([]types.TransactionID, error)
+		TransactionMetadata(id types.TransactionID) (explorer.TransactionMetadata, error)
+		Tip() types.ChainIndex
+		UnspentSiacoinElements(address types.Address, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error)
+		UnspentSiafundElements(address types.Address, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error)
+		Transactions(address types.Address, cursor explorer.Cursor, limit int) ([]types.TransactionID, explorer.Cursor, error)
+		ArbitraryDataTransactions(prefix []byte, cursor explorer.Cursor, limit int) ([]types.TransactionID, explorer.Cursor, error)
+		BalanceHistory(address types.Address, start, end uint64) ([]explorer.HistoricalBalance, error)
+		ContractHistory(id types.ElementID) ([]explorer.ContractEvent, error)
+		HostContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error)
+		RenterContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error)
+		HostStats(pubkey types.PublicKey) (explorer.HostStats, error)
+		ContractsExpiring(from, to uint64) ([]types.FileContractElement, error)
+		Attestations(pubkey types.PublicKey, key string, cursor explorer.Cursor, limit int) ([]explorer.Attestation, explorer.Cursor, error)
+		LatestAttestations(pubkey types.PublicKey) ([]explorer.Attestation, error)
+		MinerBlocks(address types.Address, cursor explorer.Cursor, limit int) ([]explorer.MinerPayout, explorer.Cursor, error)
+		MinerShares(start, end uint64) ([]explorer.MinerShare, error)
 		State(index types.ChainIndex) (context consensus.State, err error)
+		AddEventSubscriber(s explorer.EventSubscriber) types.ChainIndex
+		RemoveEventSubscriber(s explorer.EventSubscriber)
+		BlockEvents(height uint64) ([]explorer.Event, error)
 	}
 )
 
+const (
+	defaultTraceDepth = 5
+	maxTraceDepth     = 50
+
+	defaultExpiringWindow = 144
+	defaultMinerWindow    = 144
+	defaultMetricsWindow  = 144
+	defaultFeeWindow      = 144
+	defaultPoolWindow     = 144
+
+	// feeEstimateWindow is the number of recent blocks considered when
+	// estimating fees.
+	feeEstimateWindow = 10
+
+	maxSeriesPoints = 1000
+
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+
+	eventBufferSize      = 1024
+	eventCatchUpInterval = 100 * time.Millisecond
+)
+
 type server struct {
 	s  Syncer
 	e  Explorer
 	cm ChainManager
-	tp TransactionPool
+	mp Mempool
+	wm WebhookManager
 }
 
 func (s *server) txpoolBroadcastHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var tbr TxpoolBroadcastRequest
 	if err := json.NewDecoder(req.Body).Decode(&tbr); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "", err)
 		return
 	}
 	for _, txn := range tbr.DependsOn {
-		if err := s.tp.AddTransaction(txn); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if err := s.mp.AddTransaction(txn); err != nil {
+			writeBadRequest(w, "", err)
 			return
 		}
 	}
-	if err := s.tp.AddTransaction(tbr.Transaction); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := s.mp.AddTransaction(tbr.Transaction); err != nil {
+		writeBadRequest(w, "", err)
 		return
 	}
 	s.s.BroadcastTransaction(tbr.Transaction, tbr.DependsOn)
 }
 
 func (s *server) txpoolTransactionsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	WriteJSON(w, s.tp.Transactions())
+	WriteJSON(w, s.mp.Transactions())
+}
+
+func (s *server) txpoolFeeEstimateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var start uint64
+	end := s.e.Tip().Height
+	if end >= feeEstimateWindow {
+		start = end - feeEstimateWindow + 1
+	}
+	recent, err := s.e.FeeStats(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	uts := s.mp.Transactions()
+	pool := make([]types.Transaction, len(uts))
+	for i, ut := range uts {
+		pool[i] = ut.Transaction
+	}
+	WriteJSON(w, explorer.EstimateFee(recent, pool))
 }
 
 func (s *server) syncerPeersHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -93,12 +191,12 @@ func (s *server) syncerPeersHandler(w http.ResponseWriter, req *http.Request, _
 func (s *server) syncerConnectHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var scr SyncerConnectRequest
 	if err := json.NewDecoder(req.Body).Decode(&scr); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "", err)
 		return
 	}
 
 	if err := s.s.Connect(scr.NetAddress); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "", err)
 		return
 	}
 }
@@ -106,53 +204,477 @@ func (s *server) syncerConnectHandler(w http.ResponseWriter, req *http.Request,
 func (s *server) elementSiacoinHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var id types.ElementID
 	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "id", err)
 		return
 	}
 
 	elem, err := s.e.SiacoinElement(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, elem)
+	status, err := s.e.ElementStatus(id)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerSiacoinElementResponse{
+		SiacoinElement: elem,
+		Spent:          status.Spent,
+		SpentBy:        status.SpentBy,
+		SpentHeight:    status.SpentHeight,
+	})
 }
 
 func (s *server) elementSiafundHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var id types.ElementID
 	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "id", err)
 		return
 	}
 
 	elem, err := s.e.SiafundElement(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, elem)
+	status, err := s.e.ElementStatus(id)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	claim := s.e.AccruedClaim(elem)
+	if status.Spent {
+		sc, err := s.e.SiafundClaim(id)
+		if err != nil {
+			writeExplorerError(w, err)
+			return
+		}
+		claim = sc.Claim
+	}
+	WriteJSON(w, ExplorerSiafundElementResponse{
+		SiafundElement: elem,
+		Spent:          status.Spent,
+		SpentBy:        status.SpentBy,
+		SpentHeight:    status.SpentHeight,
+		Claim:          claim,
+	})
+}
+
+func (s *server) elementTraceHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var id types.ElementID
+	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
+		writeBadRequest(w, "id", err)
+		return
+	}
+	var forward bool
+	switch req.FormValue("direction") {
+	case "", "forward":
+		forward = true
+	case "backward":
+	default:
+		writeBadRequest(w, "direction", errors.New("direction must be forward or backward"))
+		return
+	}
+	depth := defaultTraceDepth
+	if req.FormValue("depth") != "" {
+		var err error
+		if depth, err = strconv.Atoi(req.FormValue("depth")); err != nil {
+			writeBadRequest(w, "depth", err)
+			return
+		} else if depth < 1 || depth > maxTraceDepth {
+			writeBadRequest(w, "depth", fmt.Errorf("depth must be between 1 and %d", maxTraceDepth))
+			return
+		}
+	}
+
+	steps, err := s.e.Trace(id, forward, depth)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, steps)
 }
 
 func (s *server) elementContractHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var id types.ElementID
 	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "id", err)
 		return
 	}
 
 	elem, err := s.e.FileContractElement(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
 	WriteJSON(w, elem)
 }
 
+func (s *server) contractHistoryHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var id types.ElementID
+	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
+		writeBadRequest(w, "id", err)
+		return
+	}
+
+	history, err := s.e.ContractHistory(id)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, history)
+}
+
+// parseContractStatus parses the status filter of a contracts request,
+// writing an error response if it is invalid.
+func parseContractStatus(w http.ResponseWriter, req *http.Request) (string, bool) {
+	switch status := req.FormValue("status"); status {
+	case "", explorer.ContractStatusActive, explorer.ContractStatusResolved, explorer.ContractStatusMissed:
+		return status, true
+	default:
+		writeBadRequest(w, "status", fmt.Errorf("invalid contract status %q", status))
+		return "", false
+	}
+}
+
+func (s *server) contractsHandler(contracts func(types.PublicKey, string, explorer.Cursor, int) ([]types.ElementID, explorer.Cursor, error)) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		var pubkey types.PublicKey
+		if err := pubkey.UnmarshalText([]byte(p.ByName("pubkey"))); err != nil {
+			writeBadRequest(w, "pubkey", err)
+			return
+		}
+		status, ok := parseContractStatus(w, req)
+		if !ok {
+			return
+		}
+		cursor, limit, ok := parsePage(w, req)
+		if !ok {
+			return
+		}
+		ids, next, err := contracts(pubkey, status, cursor, limit)
+		if err != nil {
+			writeExplorerError(w, err)
+			return
+		}
+		WriteJSON(w, ExplorerElementsResponse{IDs: ids, NextCursor: next})
+	}
+}
+
+func (s *server) contractsExpiringHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	from := s.e.Tip().Height
+	if v := req.FormValue("from"); v != "" {
+		var err error
+		if from, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "from", err)
+			return
+		}
+	}
+	to := uint64(math.MaxInt64)
+	if from < to-defaultExpiringWindow {
+		to = from + defaultExpiringWindow
+	}
+	if v := req.FormValue("to"); v != "" {
+		var err error
+		if to, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "to", err)
+			return
+		}
+	}
+	if from > to {
+		writeBadRequest(w, "from", errors.New("from must not be greater than to"))
+		return
+	}
+
+	fces, err := s.e.ContractsExpiring(from, to)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, fces)
+}
+
+func (s *server) hostHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var pubkey types.PublicKey
+	if err := pubkey.UnmarshalText([]byte(p.ByName("pubkey"))); err != nil {
+		writeBadRequest(w, "pubkey", err)
+		return
+	}
+	hs, err := s.e.HostStats(pubkey)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerHostResponse{Stats: hs, ProofSuccessRate: hs.ProofSuccessRate()})
+}
+
+func (s *server) attestationsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var pubkey types.PublicKey
+	if err := pubkey.UnmarshalText([]byte(p.ByName("pubkey"))); err != nil {
+		writeBadRequest(w, "pubkey", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	atts, next, err := s.e.Attestations(pubkey, req.FormValue("key"), cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerAttestationsResponse{Attestations: atts, NextCursor: next})
+}
+
+func (s *server) attestationsLatestHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var pubkey types.PublicKey
+	if err := pubkey.UnmarshalText([]byte(p.ByName("pubkey"))); err != nil {
+		writeBadRequest(w, "pubkey", err)
+		return
+	}
+	atts, err := s.e.LatestAttestations(pubkey)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, atts)
+}
+
+func (s *server) arbitraryDataHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	prefix, err := hex.DecodeString(p.ByName("prefix"))
+	if err != nil {
+		writeBadRequest(w, "prefix", err)
+		return
+	} else if len(prefix) > explorer.ArbitraryDataPrefixSize {
+		writeBadRequest(w, "prefix", fmt.Errorf("prefix must not exceed %d bytes", explorer.ArbitraryDataPrefixSize))
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	ids, next, err := s.e.ArbitraryDataTransactions(prefix, cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerTransactionsResponse{IDs: ids, NextCursor: next})
+}
+
+// parseWindow parses the start and end query parameters of a request for a
+// range of heights, writing an error response if they are invalid. end
+// defaults to the current tip, and start to the beginning of a window of the
+// given size ending at end.
+func (s *server) parseWindow(w http.ResponseWriter, req *http.Request, window uint64) (start, end uint64, ok bool) {
+	end = s.e.Tip().Height
+	if v := req.FormValue("end"); v != "" {
+		var err error
+		if end, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "end", err)
+			return 0, 0, false
+		}
+	}
+	if end >= window {
+		start = end - window + 1
+	}
+	if v := req.FormValue("start"); v != "" {
+		var err error
+		if start, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "start", err)
+			return 0, 0, false
+		}
+	}
+	if start > end {
+		writeBadRequest(w, "start", errors.New("start must not be greater than end"))
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func (s *server) minerBlocksHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	payouts, next, err := s.e.MinerBlocks(address, cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerMinerBlocksResponse{Blocks: payouts, NextCursor: next})
+}
+
+func (s *server) minersHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, ok := s.parseWindow(w, req, defaultMinerWindow)
+	if !ok {
+		return
+	}
+
+	shares, err := s.e.MinerShares(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, shares)
+}
+
+func (s *server) supplyStatsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, step := uint64(0), s.e.Tip().Height, uint64(1)
+	if v := req.FormValue("start"); v != "" {
+		var err error
+		if start, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "start", err)
+			return
+		}
+	}
+	if v := req.FormValue("end"); v != "" {
+		var err error
+		if end, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "end", err)
+			return
+		}
+	}
+	if v := req.FormValue("step"); v != "" {
+		var err error
+		if step, err = strconv.ParseUint(v, 10, 64); err != nil {
+			writeBadRequest(w, "step", err)
+			return
+		}
+	}
+	if start > end {
+		writeBadRequest(w, "start", errors.New("start must not be greater than end"))
+		return
+	} else if step == 0 {
+		writeBadRequest(w, "step", errors.New("step must be positive"))
+		return
+	} else if (end-start)/step >= maxSeriesPoints {
+		writeBadRequest(w, "step", fmt.Errorf("series must not exceed %d points", maxSeriesPoints))
+		return
+	}
+
+	series, err := s.e.SupplyStats(start, end, step)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, series)
+}
+
+func (s *server) metricsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, ok := s.parseWindow(w, req, defaultMetricsWindow)
+	if !ok {
+		return
+	}
+	nm, err := s.e.NetworkMetrics(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, nm)
+}
+
+func (s *server) metricsBlocksHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, ok := s.parseWindow(w, req, defaultMetricsWindow)
+	if !ok {
+		return
+	} else if end-start >= maxSeriesPoints {
+		writeBadRequest(w, "start", fmt.Errorf("series must not exceed %d points", maxSeriesPoints))
+		return
+	}
+	metrics, err := s.e.BlockMetrics(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, metrics)
+}
+
+func (s *server) feeStatsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, ok := s.parseWindow(w, req, defaultFeeWindow)
+	if !ok {
+		return
+	} else if end-start >= maxSeriesPoints {
+		writeBadRequest(w, "start", fmt.Errorf("series must not exceed %d points", maxSeriesPoints))
+		return
+	}
+	stats, err := s.e.FeeStats(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, stats)
+}
+
+func (s *server) siafundPoolHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start, end, ok := s.parseWindow(w, req, defaultPoolWindow)
+	if !ok {
+		return
+	} else if end-start >= maxSeriesPoints {
+		writeBadRequest(w, "start", fmt.Errorf("series must not exceed %d points", maxSeriesPoints))
+		return
+	}
+	history, err := s.e.SiafundPoolHistory(start, end)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, history)
+}
+
+func (s *server) foundationHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	height := s.e.Tip().Height
+	if v := req.FormValue("height"); v != "" {
+		var err error
+		if height, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "height", err)
+			return
+		}
+	}
+	address, err := s.e.FoundationAddress(height)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerFoundationResponse{Height: height, Address: address})
+}
+
+func (s *server) foundationChangesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	changes, next, err := s.e.FoundationChanges(cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerFoundationChangesResponse{Changes: changes, NextCursor: next})
+}
+
+func (s *server) foundationSubsidiesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	subsidies, next, err := s.e.FoundationSubsidies(cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerFoundationSubsidiesResponse{Subsidies: subsidies, NextCursor: next})
+}
+
 func (s *server) chainStatsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	if p.ByName("index") == "tip" {
 		facts, err := s.e.ChainStatsLatest()
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeExplorerError(w, err)
 			return
 		}
 		WriteJSON(w, facts)
@@ -161,13 +683,13 @@ func (s *server) chainStatsHandler(w http.ResponseWriter, req *http.Request, p h
 
 	index, err := types.ParseChainIndex(p.ByName("index"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "index", err)
 		return
 	}
 
 	facts, err := s.e.ChainStats(index)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
 	WriteJSON(w, facts)
@@ -176,22 +698,45 @@ func (s *server) chainStatsHandler(w http.ResponseWriter, req *http.Request, p h
 func (s *server) chainStateHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	index, err := types.ParseChainIndex(p.ByName("index"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "index", err)
 		return
 	}
 
 	vc, err := s.e.State(index)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
 	WriteJSON(w, vc)
 }
 
+func (s *server) blockHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var block types.Block
+	if height, err := parseHeight(p.ByName("heightOrID")); err == nil {
+		block, err = s.e.BlockByHeight(height)
+		if err != nil {
+			writeExplorerError(w, err)
+			return
+		}
+	} else {
+		var id types.BlockID
+		if err := id.UnmarshalText([]byte(p.ByName("heightOrID"))); err != nil {
+			writeBadRequest(w, "heightOrID", err)
+			return
+		}
+		block, err = s.e.BlockByID(id)
+		if err != nil {
+			writeExplorerError(w, err)
+			return
+		}
+	}
+	WriteJSON(w, block)
+}
+
 func (s *server) elementSearchHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var id types.ElementID
 	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "id", err)
 		return
 	}
 
@@ -205,241 +750,898 @@ func (s *server) elementSearchHandler(w http.ResponseWriter, req *http.Request,
 	} else if elem, err := s.e.FileContractElement(id); err == nil {
 		response.Type = "contract"
 		response.FileContractElement = elem
+	} else {
+		writeExplorerError(w, explorer.ErrNotFound)
+		return
 	}
 	WriteJSON(w, response)
 }
 
+// search returns the objects matching query, which may be a block height or
+// the string form of a block ID, transaction ID, element ID, address, or
+// host public key. Since hex identifiers may be given without their prefix, a
+// query can match objects of several types.
+// hexLength reports whether query holds n characters once prefix is removed.
+// The core decoders write past the end of their fixed-size arrays when given
+// too much hex, so search checks the length before decoding.
+func hexLength(query, prefix string, n int) bool {
+	return len(strings.TrimPrefix(query, prefix)) == n
+}
+
+func (s *server) search(query string) ([]ExplorerSearchResult, error) {
+	var results []ExplorerSearchResult
+	// found records a match if err is nil, ignoring ErrNotFound
+	found := func(typ, id string, err error) error {
+		if err == nil {
+			results = append(results, ExplorerSearchResult{Type: typ, ID: id})
+		} else if !errors.Is(err, explorer.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	if height, err := parseHeight(query); err == nil {
+		b, err := s.e.BlockByHeight(height)
+		if err := found(SearchResultBlock, b.ID().String(), err); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	var bid types.BlockID
+	if hexLength(query, "bid:", 64) && bid.UnmarshalText([]byte(query)) == nil {
+		_, err := s.e.BlockByID(bid)
+		if err := found(SearchResultBlock, bid.String(), err); err != nil {
+			return nil, err
+		}
+	}
+	var txid types.TransactionID
+	if hexLength(query, "txid:", 64) && txid.UnmarshalText([]byte(query)) == nil {
+		_, err := s.e.Transaction(txid)
+		if err := found(SearchResultTransaction, txid.String(), err); err != nil {
+			return nil, err
+		}
+	}
+	var eid types.ElementID
+	if parts := strings.Split(query, ":"); len(parts) == 3 && len(parts[1]) == 64 && eid.UnmarshalText([]byte(query)) == nil {
+		_, err := s.e.SiacoinElement(eid)
+		if err := found(SearchResultSiacoinElement, eid.String(), err); err != nil {
+			return nil, err
+		}
+		_, err = s.e.SiafundElement(eid)
+		if err := found(SearchResultSiafundElement, eid.String(), err); err != nil {
+			return nil, err
+		}
+		_, err = s.e.FileContractElement(eid)
+		if err := found(SearchResultContract, eid.String(), err); err != nil {
+			return nil, err
+		}
+	}
+	var addr types.Address
+	if hexLength(query, "addr:", 76) && addr.UnmarshalText([]byte(query)) == nil {
+		// an address is known if it holds a balance, or has been involved
+		// in a transaction or paid by a block. Payouts received outside of
+		// transactions, such as contract resolutions and siafund claims,
+		// either remain in the balance or are spent by a transaction.
+		known := func() (bool, error) {
+			if sc, sf, err := s.e.AddressBalance(addr); err != nil || !sc.IsZero() || sf != 0 {
+				return err == nil, err
+			} else if txids, _, err := s.e.Transactions(addr, "", 1); err != nil || len(txids) != 0 {
+				return err == nil, err
+			}
+			payouts, _, err := s.e.MinerBlocks(addr, "", 1)
+			return len(payouts) != 0, err
+		}
+		ok, err := known()
+		if err == nil && !ok {
+			err = explorer.ErrNotFound
+		}
+		if err := found(SearchResultAddress, addr.String(), err); err != nil {
+			return nil, err
+		}
+	}
+	var pubkey types.PublicKey
+	if hexLength(query, "ed25519:", 64) && pubkey.UnmarshalText([]byte(query)) == nil {
+		_, err := s.e.HostStats(pubkey)
+		if err := found(SearchResultHost, pubkey.String(), err); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (s *server) searchHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	query := strings.TrimSpace(p.ByName("query"))
+	results, err := s.search(query)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	} else if len(results) == 0 {
+		writeExplorerError(w, fmt.Errorf("%w: nothing matches %q", explorer.ErrNotFound, query))
+		return
+	}
+	WriteJSON(w, results)
+}
+
 func (s *server) addressBalanceHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var address types.Address
-	if err := json.Unmarshal([]byte(p.ByName("address")), &address); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
 		return
 	}
-	scBalance, err := s.e.SiacoinBalance(address)
+	scBalance, sfBalance, err := s.e.AddressBalance(address)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	sfBalance, err := s.e.SiafundBalance(address)
+	rank, err := s.e.AddressRank(address)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, ExplorerWalletBalanceResponse{scBalance, sfBalance})
+	WriteJSON(w, ExplorerWalletBalanceResponse{
+		Siacoins:          scBalance,
+		Siafunds:          sfBalance,
+		SiacoinRank:       rank.SiacoinRank,
+		SiacoinPercentile: rank.SiacoinPercentile(),
+		SiafundRank:       rank.SiafundRank,
+		SiafundPercentile: rank.SiafundPercentile(),
+	})
 }
 
-func (s *server) addressSiacoinsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+func (s *server) richListHandler(richList func(explorer.Cursor, int) ([]explorer.RankedBalance, explorer.Cursor, error)) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		cursor, limit, ok := parsePage(w, req)
+		if !ok {
+			return
+		}
+		balances, next, err := richList(cursor, limit)
+		if err != nil {
+			writeExplorerError(w, err)
+			return
+		}
+		WriteJSON(w, ExplorerRichListResponse{Balances: balances, NextCursor: next})
+	}
+}
+
+func (s *server) addressHistoryHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var address types.Address
-	if err := json.Unmarshal([]byte(p.ByName("address")), &address); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
 		return
 	}
-	outputs, err := s.e.UnspentSiacoinElements(address)
+	start, end := uint64(0), s.e.Tip().Height
+	if v := req.FormValue("start"); v != "" {
+		var err error
+		if start, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "start", err)
+			return
+		}
+	}
+	if v := req.FormValue("end"); v != "" {
+		var err error
+		if end, err = parseHeight(v); err != nil {
+			writeBadRequest(w, "end", err)
+			return
+		}
+	}
+	if start > end {
+		writeBadRequest(w, "start", errors.New("start must not be greater than end"))
+		return
+	}
+
+	history, err := s.e.BalanceHistory(address, start, end)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
+	WriteJSON(w, history)
+}
 
-	WriteJSON(w, outputs)
+func (s *server) addressSiacoinsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	ids, next, err := s.e.UnspentSiacoinElements(address, cursor, limit)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerElementsResponse{IDs: ids, NextCursor: next})
 }
 
 func (s *server) addressSiafundsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var address types.Address
-	if err := json.Unmarshal([]byte(p.ByName("address")), &address); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
 		return
 	}
-	outputs, err := s.e.UnspentSiafundElements(address)
+	ids, next, err := s.e.UnspentSiafundElements(address, cursor, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, outputs)
+	WriteJSON(w, ExplorerElementsResponse{IDs: ids, NextCursor: next})
 }
 
-func (s *server) addressTransactionsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+func (s *server) addressClaimsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var address types.Address
-	if err := json.Unmarshal([]byte(p.ByName("address")), &address); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
 		return
 	}
-	amount, err := strconv.Atoi(req.FormValue("amount"))
+	claims, next, err := s.e.SiafundClaims(address, cursor, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, ExplorerSiafundClaimsResponse{Claims: claims, NextCursor: next})
+}
+
+func (s *server) addressRevenueHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
 		return
 	}
-	offset, err := strconv.Atoi(req.FormValue("offset"))
+	revenue, err := s.e.SiafundRevenue(address)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, revenue)
+}
+
+func (s *server) addressPendingHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
 		return
 	}
+	WriteJSON(w, s.mp.PendingActivity(address))
+}
 
-	ids, err := s.e.Transactions(address, amount, offset)
+func (s *server) addressTransactionsHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(p.ByName("address"))); err != nil {
+		writeBadRequest(w, "address", err)
+		return
+	}
+	cursor, limit, ok := parsePage(w, req)
+	if !ok {
+		return
+	}
+	ids, next, err := s.e.Transactions(address, cursor, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, ids)
+	WriteJSON(w, ExplorerTransactionsResponse{IDs: ids, NextCursor: next})
 }
 
 func (s *server) transactionHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	var id types.TransactionID
-	if err := json.Unmarshal([]byte(p.ByName("id")), &id); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := id.UnmarshalText([]byte(p.ByName("id"))); err != nil {
+		writeBadRequest(w, "id", err)
 		return
 	}
 	txn, err := s.e.Transaction(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeExplorerError(w, err)
 		return
 	}
-	WriteJSON(w, txn)
+	meta, err := s.e.TransactionMetadata(id)
+	if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	var confirmations uint64
+	if tip := s.e.Tip(); tip.Height >= meta.Block.Height {
+		confirmations = tip.Height - meta.Block.Height + 1
+	}
+	WriteJSON(w, ExplorerTransactionResponse{
+		Transaction:   txn,
+		Height:        meta.Block.Height,
+		BlockID:       meta.Block.ID,
+		Index:         meta.Index,
+		Timestamp:     meta.Timestamp,
+		Confirmations: confirmations,
+	})
 }
 
 func (s *server) batchAddressesBalanceHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var addresses []types.Address
 	if err := json.NewDecoder(req.Body).Decode(&addresses); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, "", err)
 		return
 	}
 
 	var balances []ExplorerWalletBalanceResponse
 	for _, address := range addresses {
-		scBalance, err := s.e.SiacoinBalance(address)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		sfBalance, err := s.e.SiafundBalance(address)
+		scBalance, sfBalance, err := s.e.AddressBalance(address)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeExplorerError(w, err)
 			return
 		}
-		balances = append(balances, ExplorerWalletBalanceResponse{scBalance, sfBalance})
+		balances = append(balances, ExplorerWalletBalanceResponse{Siacoins: scBalance, Siafunds: sfBalance})
 	}
 	WriteJSON(w, balances)
 }
 
 func (s *server) batchAddressesSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	var addresses []types.Address
-	if err := json.NewDecoder(req.Body).Decode(&addresses); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var reqs []ExplorerAddressPageRequest
+	if err := json.NewDecoder(req.Body).Decode(&reqs); err != nil {
+		writeBadRequest(w, "", err)
 		return
 	}
 
-	var elems [][]types.SiacoinElement
-	for _, address := range addresses {
-		ids, err := s.e.UnspentSiacoinElements(address)
+	resps := make([]ExplorerBatchSiacoinsResponse, 0, len(reqs))
+	for _, r := range reqs {
+		ids, next, err := s.e.UnspentSiacoinElements(r.Address, r.Cursor, batchPageLimit(r.Limit))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeExplorerError(w, err)
 			return
 		}
-		var elemsList []types.SiacoinElement
+		resp := ExplorerBatchSiacoinsResponse{Elements: []types.SiacoinElement{}, NextCursor: next}
 		for _, id := range ids {
 			elem, err := s.e.SiacoinElement(id)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeExplorerError(w, err)
 				return
 			}
-			elemsList = append(elemsList, elem)
+			resp.Elements = append(resp.Elements, elem)
 		}
-		elems = append(elems, elemsList)
+		resps = append(resps, resp)
 	}
-	WriteJSON(w, elems)
+	WriteJSON(w, resps)
 }
 
 func (s *server) batchAddressesSiafundsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	var addresses []types.Address
-	if err := json.NewDecoder(req.Body).Decode(&addresses); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var reqs []ExplorerAddressPageRequest
+	if err := json.NewDecoder(req.Body).Decode(&reqs); err != nil {
+		writeBadRequest(w, "", err)
 		return
 	}
 
-	var elems [][]types.SiafundElement
-	for _, address := range addresses {
-		ids, err := s.e.UnspentSiafundElements(address)
+	resps := make([]ExplorerBatchSiafundsResponse, 0, len(reqs))
+	for _, r := range reqs {
+		ids, next, err := s.e.UnspentSiafundElements(r.Address, r.Cursor, batchPageLimit(r.Limit))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeExplorerError(w, err)
 			return
 		}
-		var elemsList []types.SiafundElement
+		resp := ExplorerBatchSiafundsResponse{Elements: []types.SiafundElement{}, NextCursor: next}
 		for _, id := range ids {
 			elem, err := s.e.SiafundElement(id)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeExplorerError(w, err)
 				return
 			}
-			elemsList = append(elemsList, elem)
+			resp.Elements = append(resp.Elements, elem)
 		}
-		elems = append(elems, elemsList)
+		resps = append(resps, resp)
 	}
-	WriteJSON(w, elems)
+	WriteJSON(w, resps)
 }
 
 func (s *server) batchAddressesTransactionsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	var etrs []ExplorerTransactionsRequest
-	if err := json.NewDecoder(req.Body).Decode(&etrs); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var reqs []ExplorerAddressPageRequest
+	if err := json.NewDecoder(req.Body).Decode(&reqs); err != nil {
+		writeBadRequest(w, "", err)
 		return
 	}
 
-	var txns [][]types.Transaction
-	for _, etr := range etrs {
-		ids, err := s.e.Transactions(etr.Address, etr.Amount, etr.Offset)
+	resps := make([]ExplorerBatchTransactionsResponse, 0, len(reqs))
+	for _, r := range reqs {
+		ids, next, err := s.e.Transactions(r.Address, r.Cursor, batchPageLimit(r.Limit))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeExplorerError(w, err)
 			return
 		}
-		var txnsList []types.Transaction
+		resp := ExplorerBatchTransactionsResponse{Transactions: []types.Transaction{}, NextCursor: next}
 		for _, id := range ids {
 			txn, err := s.e.Transaction(id)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeExplorerError(w, err)
 				return
 			}
-			txnsList = append(txnsList, txn)
+			resp.Transactions = append(resp.Transactions, txn)
 		}
-		txns = append(txns, txnsList)
+		resps = append(resps, resp)
 	}
-	WriteJSON(w, txns)
+	WriteJSON(w, resps)
 }
 
-// NewServer returns an HTTP handler that serves the explorerd API.
-func NewServer(cm ChainManager, s Syncer, tp TransactionPool, e Explorer) http.Handler {
-	srv := server{
-		cm: cm,
-		s:  s,
-		tp: tp,
-		e:  e,
+// writeError writes apiErr to w with the given status code.
+func writeError(w http.ResponseWriter, status int, apiErr Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiErr)
+}
+
+// writeBadRequest writes a 400 response. If param is non-empty, it names the
+// malformed request parameter.
+func writeBadRequest(w http.ResponseWriter, param string, err error) {
+	apiErr := Error{Code: ErrorCodeBadRequest, Message: err.Error()}
+	if param != "" {
+		apiErr.Details = map[string]string{"param": param}
 	}
-	mux := httprouter.New()
+	writeError(w, http.StatusBadRequest, apiErr)
+}
+
+// writeExplorerError writes the response for an error returned by the
+// explorer.
+func writeExplorerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, explorer.ErrInvalidCursor):
+		writeBadRequest(w, "cursor", err)
+	case errors.Is(err, explorer.ErrNotFound):
+		writeError(w, http.StatusNotFound, Error{Code: ErrorCodeNotFound, Message: err.Error()})
+	case errors.Is(err, explorer.ErrUnavailable):
+		writeError(w, http.StatusServiceUnavailable, Error{Code: ErrorCodeUnavailable, Message: err.Error()})
+	default:
+		writeError(w, http.StatusInternalServerError, Error{Code: ErrorCodeInternal, Message: err.Error()})
+	}
+}
+
+// parsePage parses the cursor and limit parameters of a paginated request. If
+// they are invalid, it writes an error to w and returns false.
+// parseHeight parses a block height. Heights are stored as signed 64-bit
+// integers, so larger values are rejected.
+func parseHeight(v string) (uint64, error) {
+	height, err := strconv.ParseUint(v, 10, 64)
+	if err == nil && height > math.MaxInt64 {
+		err = fmt.Errorf("height must not exceed %d", uint64(math.MaxInt64))
+	}
+	return height, err
+}
+
+func parsePage(w http.ResponseWriter, req *http.Request) (cursor explorer.Cursor, limit int, ok bool) {
+	cursor, limit = explorer.Cursor(req.FormValue("cursor")), defaultPageLimit
+	if v := req.FormValue("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeBadRequest(w, "limit", err)
+			return "", 0, false
+		} else if limit < 1 || limit > maxPageLimit {
+			writeBadRequest(w, "limit", fmt.Errorf("limit must be between 1 and %d", maxPageLimit))
+			return "", 0, false
+		}
+	}
+	return cursor, limit, true
+}
 
-	mux.GET("/txpool/transactions", srv.txpoolTransactionsHandler)
-	mux.POST("/txpool/broadcast", srv.txpoolBroadcastHandler)
+// batchPageLimit returns the page size for one request in a batch, applying
+// the default and cap used by parsePage.
+func batchPageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	} else if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
 
-	mux.GET("/syncer/peers", srv.syncerPeersHandler)
-	mux.POST("/syncer/connect", srv.syncerConnectHandler)
+// APIVersion is the version of the API served by NewServer.
+const APIVersion = "v1"
 
-	mux.GET("/element/search/:id", srv.elementSearchHandler)
-	mux.GET("/element/siacoin/:id", srv.elementSiacoinHandler)
-	mux.GET("/element/siafund/:id", srv.elementSiafundHandler)
-	mux.GET("/element/contract/:id", srv.elementContractHandler)
+// A routeGroup registers routes on a router beneath a common prefix.
+type routeGroup struct {
+	router *httprouter.Router
+	prefix string
+}
 
-	mux.GET("/chain/:index", srv.chainStatsHandler)
-	mux.GET("/chain/:index/state", srv.chainStateHandler)
+func (g routeGroup) group(prefix string) routeGroup {
+	return routeGroup{g.router, g.prefix + prefix}
+}
 
-	mux.GET("/transaction/:id", srv.transactionHandler)
+func (g routeGroup) GET(path string, handle httprouter.Handle) {
+	g.router.GET(g.prefix+path, handle)
+}
 
-	mux.GET("/address/:address/balance", srv.addressBalanceHandler)
-	mux.GET("/address/:address/siacoins", srv.addressSiacoinsHandler)
-	mux.GET("/address/:address/siafunds", srv.addressSiacoinsHandler)
-	mux.GET("/address/:address/transactions", srv.addressTransactionsHandler)
+func (g routeGroup) POST(path string, handle httprouter.Handle) {
+	g.router.POST(g.prefix+path, handle)
+}
 
-	mux.POST("/batch/addresses/balance", srv.batchAddressesBalanceHandler)
-	mux.POST("/batch/addresses/siacoins", srv.batchAddressesSiacoinsHandler)
-	mux.POST("/batch/addresses/siafunds", srv.batchAddressesSiafundsHandler)
-	mux.POST("/batch/addresses/transactions", srv.batchAddressesTransactionsHandler)
+func (g routeGroup) DELETE(path string, handle httprouter.Handle) {
+	g.router.DELETE(g.prefix+path, handle)
+}
 
+func (s *server) webhooksHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, s.wm.Webhooks())
+}
+
+func (s *server) webhooksAddHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var ewr ExplorerWebhookRequest
+	if err := json.NewDecoder(req.Body).Decode(&ewr); err != nil {
+		writeBadRequest(w, "", err)
+		return
+	}
+	wh, err := s.wm.AddWebhook(explorer.Webhook{
+		URL:       ewr.URL,
+		Secret:    ewr.Secret,
+		Addresses: ewr.Addresses,
+		Contracts: ewr.Contracts,
+	})
+	if errors.Is(err, explorer.ErrInvalidWebhook) {
+		writeBadRequest(w, "", err)
+		return
+	} else if err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+	WriteJSON(w, wh)
+}
+
+func (s *server) webhooksRemoveHandler(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	id, err := strconv.ParseInt(p.ByName("id"), 10, 64)
+	if err != nil {
+		writeBadRequest(w, "id", err)
+		return
+	}
+	if err := s.wm.RemoveWebhook(id); err != nil {
+		writeExplorerError(w, err)
+		return
+	}
+}
+
+// An eventSubscriber buffers the events published by the explorer for a
+// single event stream. If the stream falls too far behind, the subscriber
+// closes overflow, and the stream is terminated; the client can then resume
+// from the last event it received.
+type eventSubscriber struct {
+	events   chan explorer.Event
+	overflow chan struct{}
+	once     sync.Once
+}
+
+func (es *eventSubscriber) ProcessEvent(ev explorer.Event) {
+	select {
+	case es.events <- ev:
+	default:
+		es.once.Do(func() { close(es.overflow) })
+	}
+}
+
+// An eventFilter restricts an event stream to transactions involving
+// particular addresses or elements. Block events are always included.
+type eventFilter struct {
+	addresses map[types.Address]bool
+	elements  map[types.ElementID]bool
+}
+
+func (f eventFilter) match(ev explorer.Event) bool {
+	if ev.Type == explorer.EventBlockApplied || ev.Type == explorer.EventBlockReverted {
+		return true
+	} else if len(f.addresses) == 0 && len(f.elements) == 0 {
+		return true
+	}
+	for _, addr := range ev.Addresses {
+		if f.addresses[addr] {
+			return true
+		}
+	}
+	for _, id := range ev.Elements {
+		if f.elements[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEvent writes ev to w in the text/event-stream format. Block events
+// carry the explorer's resulting tip as their ID, so that a reconnecting
+// client can resume from it via the Last-Event-ID header.
+func writeEvent(w http.ResponseWriter, ev explorer.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if ev.Type == explorer.EventBlockApplied || ev.Type == explorer.EventBlockReverted {
+		id, _ := ev.Tip.MarshalText()
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+// onBestChain reports whether index is on the chain manager's best chain.
+func (s *server) onBestChain(index types.ChainIndex) (bool, error) {
+	tip := s.cm.TipState().Index
+	for tip.Height > index.Height {
+		b, err := s.cm.Block(tip)
+		if err != nil {
+			return false, err
+		}
+		tip = b.Header.ParentIndex()
+	}
+	return tip == index, nil
+}
+
+func (s *server) eventsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, Error{Code: ErrorCodeInternal, Message: "streaming is not supported"})
+		return
+	}
+
+	var filter eventFilter
+	for _, v := range req.URL.Query()["address"] {
+		var addr types.Address
+		if err := addr.UnmarshalText([]byte(v)); err != nil {
+			writeBadRequest(w, "address", err)
+			return
+		}
+		if filter.addresses == nil {
+			filter.addresses = make(map[types.Address]bool)
+		}
+		filter.addresses[addr] = true
+	}
+	for _, v := range req.URL.Query()["element"] {
+		var id types.ElementID
+		if err := id.UnmarshalText([]byte(v)); err != nil {
+			writeBadRequest(w, "element", err)
+			return
+		}
+		if filter.elements == nil {
+			filter.elements = make(map[types.ElementID]bool)
+		}
+		filter.elements[id] = true
+	}
+	since := req.FormValue("since")
+	if since == "" {
+		since = req.Header.Get("Last-Event-ID")
+	}
+	var resume *types.ChainIndex
+	if since != "" {
+		index, err := types.ParseChainIndex(since)
+		if err != nil {
+			writeBadRequest(w, "since", err)
+			return
+		}
+		resume = &index
+	}
+
+	// subscribe before replaying, so that no block is missed in between
+	var es *eventSubscriber
+	var tip types.ChainIndex
+	unsubscribe := func() {
+		s.e.RemoveEventSubscriber(es)
+		s.mp.RemoveEventSubscriber(es)
+	}
+	var reverted []explorer.Event
+subscribe:
+	for {
+		es = &eventSubscriber{
+			events:   make(chan explorer.Event, eventBufferSize),
+			overflow: make(chan struct{}),
+		}
+		tip = s.e.AddEventSubscriber(es)
+		s.mp.AddEventSubscriber(es)
+
+		// if the resume point was reorged out, walk back to the best chain,
+		// reverting each orphaned block
+		for resume != nil {
+			b, err := s.e.BlockByHeight(resume.Height)
+			if err == nil && b.ID() == resume.ID {
+				break
+			} else if err != nil && !errors.Is(err, explorer.ErrNotFound) {
+				unsubscribe()
+				writeExplorerError(w, err)
+				return
+			}
+			if best, err := s.onBestChain(*resume); err != nil {
+				unsubscribe()
+				writeExplorerError(w, err)
+				return
+			} else if best {
+				// the explorer has yet to process the resume point; wait
+				// for it to catch up, then subscribe again
+				unsubscribe()
+				select {
+				case <-req.Context().Done():
+					return
+				case <-time.After(eventCatchUpInterval):
+				}
+				continue subscribe
+			}
+			orphan, err := s.cm.Block(*resume)
+			if err != nil {
+				unsubscribe()
+				writeError(w, http.StatusNotFound, Error{Code: ErrorCodeNotFound, Message: "chain index is unknown"})
+				return
+			}
+			parent, err := s.cm.State(orphan.Header.ParentIndex())
+			if err != nil {
+				unsubscribe()
+				writeError(w, http.StatusNotFound, Error{Code: ErrorCodeNotFound, Message: "chain index is unknown"})
+				return
+			}
+			ru := consensus.RevertBlock(parent, orphan)
+			reverted = append(reverted, explorer.RevertedBlockEvents(orphan, ru.NewSiacoinElements)...)
+			*resume = orphan.Header.ParentIndex()
+		}
+		break
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(ev explorer.Event) bool {
+		if !filter.match(ev) {
+			return true
+		} else if err := writeEvent(w, ev); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	// while replaying, hold live events aside rather than letting them
+	// overflow the subscriber's buffer
+	var live []explorer.Event
+	drain := func() {
+		for {
+			select {
+			case ev := <-es.events:
+				live = append(live, ev)
+			default:
+				return
+			}
+		}
+	}
+	replay := func(events []explorer.Event) bool {
+		for _, ev := range events {
+			drain()
+			if !send(ev) {
+				return false
+			}
+		}
+		return true
+	}
+	if !replay(reverted) {
+		return
+	}
+	if resume != nil {
+		for height := resume.Height + 1; height <= tip.Height; height++ {
+			events, err := s.e.BlockEvents(height)
+			if err != nil || !replay(events) {
+				return
+			}
+		}
+	}
+	drain()
+	if !replay(live) {
+		return
+	}
+
+	for {
+		select {
+		case ev := <-es.events:
+			if !send(ev) {
+				return
+			}
+		case <-es.overflow:
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// mount registers the API routes beneath api.
+func (srv *server) mount(api routeGroup) {
+	txpool := api.group("/txpool")
+	txpool.GET("/transactions", srv.txpoolTransactionsHandler)
+	txpool.POST("/broadcast", srv.txpoolBroadcastHandler)
+	txpool.GET("/fee-estimate", srv.txpoolFeeEstimateHandler)
+
+	syncer := api.group("/syncer")
+	syncer.GET("/peers", srv.syncerPeersHandler)
+	syncer.POST("/connect", srv.syncerConnectHandler)
+
+	explorer := api.group("/explorer")
+	explorer.GET("/search/:query", srv.searchHandler)
+	explorer.GET("/element/search/:id", srv.elementSearchHandler)
+	explorer.GET("/element/siacoin/:id", srv.elementSiacoinHandler)
+	explorer.GET("/element/siafund/:id", srv.elementSiafundHandler)
+	explorer.GET("/element/contract/:id", srv.elementContractHandler)
+	explorer.GET("/element/trace/:id", srv.elementTraceHandler)
+
+	explorer.GET("/events", srv.eventsHandler)
+
+	explorer.GET("/webhooks", srv.webhooksHandler)
+	explorer.POST("/webhooks", srv.webhooksAddHandler)
+	explorer.DELETE("/webhooks/:id", srv.webhooksRemoveHandler)
+
+	explorer.GET("/contract/:id/history", srv.contractHistoryHandler)
+	explorer.GET("/contracts/expiring", srv.contractsExpiringHandler)
+	explorer.GET("/host/:pubkey", srv.hostHandler)
+	explorer.GET("/host/:pubkey/contracts", srv.contractsHandler(srv.e.HostContracts))
+	explorer.GET("/renter/:pubkey/contracts", srv.contractsHandler(srv.e.RenterContracts))
+
+	explorer.GET("/attestations/:pubkey", srv.attestationsHandler)
+	explorer.GET("/attestations/:pubkey/latest", srv.attestationsLatestHandler)
+
+	explorer.GET("/arbitrary/:prefix", srv.arbitraryDataHandler)
+
+	explorer.GET("/miner/:address/blocks", srv.minerBlocksHandler)
+	explorer.GET("/miners", srv.minersHandler)
+
+	explorer.GET("/stats/supply", srv.supplyStatsHandler)
+	explorer.GET("/metrics", srv.metricsHandler)
+	explorer.GET("/metrics/blocks", srv.metricsBlocksHandler)
+	explorer.GET("/fees", srv.feeStatsHandler)
+	explorer.GET("/siafunds/pool", srv.siafundPoolHandler)
+
+	explorer.GET("/foundation", srv.foundationHandler)
+	explorer.GET("/foundation/changes", srv.foundationChangesHandler)
+	explorer.GET("/foundation/subsidies", srv.foundationSubsidiesHandler)
+
+	explorer.GET("/chain/:index", srv.chainStatsHandler)
+	explorer.GET("/chain/:index/state", srv.chainStateHandler)
+
+	explorer.GET("/block/:heightOrID", srv.blockHandler)
+
+	explorer.GET("/transaction/:id", srv.transactionHandler)
+
+	explorer.GET("/richlist/siacoins", srv.richListHandler(srv.e.SiacoinRichList))
+	explorer.GET("/richlist/siafunds", srv.richListHandler(srv.e.SiafundRichList))
+
+	explorer.GET("/address/:address/balance", srv.addressBalanceHandler)
+	explorer.GET("/address/:address/history", srv.addressHistoryHandler)
+	explorer.GET("/address/:address/siacoins", srv.addressSiacoinsHandler)
+	explorer.GET("/address/:address/siafunds", srv.addressSiafundsHandler)
+	explorer.GET("/address/:address/transactions", srv.addressTransactionsHandler)
+	explorer.GET("/address/:address/claims", srv.addressClaimsHandler)
+	explorer.GET("/address/:address/revenue", srv.addressRevenueHandler)
+	explorer.GET("/address/:address/pending", srv.addressPendingHandler)
+
+	explorer.POST("/batch/addresses/balance", srv.batchAddressesBalanceHandler)
+	explorer.POST("/batch/addresses/siacoins", srv.batchAddressesSiacoinsHandler)
+	explorer.POST("/batch/addresses/siafunds", srv.batchAddressesSiafundsHandler)
+	explorer.POST("/batch/addresses/transactions", srv.batchAddressesTransactionsHandler)
+}
+
+// NewServer returns an HTTP handler that serves the explorerd API. All routes
+// are mounted beneath /api/<APIVersion>, leaving the rest of the namespace free
+// for other content such as a UI. The same routes are also mounted beneath
+// /api, which always serves the latest version.
+func NewServer(cm ChainManager, s Syncer, mp Mempool, e Explorer, wm WebhookManager) http.Handler {
+	srv := server{
+		cm: cm,
+		s:  s,
+		mp: mp,
+		e:  e,
+		wm: wm,
+	}
+	mux := httprouter.New()
+	mux.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeError(w, http.StatusNotFound, Error{Code: ErrorCodeNotFound, Message: "no such route"})
+	})
+	mux.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrorCodeBadRequest, Message: "method not allowed"})
+	})
+	srv.mount(routeGroup{mux, "/api/" + APIVersion})
+	srv.mount(routeGroup{mux, "/api"})
 	return mux
 }
 
@@ -447,7 +1649,7 @@ func NewServer(cm ChainManager, s Syncer, tp TransactionPool, e Explorer) http.H
 func AuthMiddleware(handler http.Handler, requiredPass string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if _, password, ok := req.BasicAuth(); !ok || password != requiredPass {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			writeError(w, http.StatusUnauthorized, Error{Code: ErrorCodeUnauthorized, Message: "invalid password"})
 			return
 		}
 		handler.ServeHTTP(w, req)
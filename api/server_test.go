@@ -0,0 +1,684 @@
+package api_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.sia.tech/core/chain"
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+	"go.sia.tech/explorer"
+	"go.sia.tech/explorer/api"
+	"go.sia.tech/explorer/internal/chainutil"
+	"go.sia.tech/explorer/internal/explorerutil"
+	"go.sia.tech/explorer/internal/walletutil"
+	"go.sia.tech/siad/v2/txpool"
+)
+
+func testingKeypair(seed uint64) (types.PublicKey, types.PrivateKey) {
+	var b [32]byte
+	binary.LittleEndian.PutUint64(b[:], seed)
+	privkey := types.NewPrivateKeyFromSeed(b)
+	return privkey.PublicKey(), privkey
+}
+
+type stubSyncer struct {
+	peers []string
+}
+
+func (s *stubSyncer) Addr() string    { return "127.0.0.1:9981" }
+func (s *stubSyncer) Peers() []string { return s.peers }
+func (s *stubSyncer) Connect(addr string) error {
+	s.peers = append(s.peers, addr)
+	return nil
+}
+func (s *stubSyncer) BroadcastTransaction(txn types.Transaction, dependsOn []types.Transaction) {}
+
+type testNode struct {
+	sim *chainutil.ChainSim
+	cm  *chain.Manager
+	tp  *txpool.Pool
+	e   *explorer.Explorer
+	s   *stubSyncer
+	c   *api.Client
+}
+
+func newTestNode(tb testing.TB) *testNode {
+	sim := chainutil.NewChainSim()
+	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.State)
+	tp := txpool.New(sim.Genesis.State)
+	cm.AddSubscriber(tp, cm.Tip())
+	mp := explorer.NewMempool(tp)
+	cm.AddSubscriber(mp, cm.Tip())
+	tb.Cleanup(func() { mp.Close() })
+
+	hs, err := explorerutil.NewHashStore(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{
+		ApplyUpdate: consensus.GenesisUpdate(sim.Genesis.Block, types.Work{NumHashes: [32]byte{31: 4}}),
+		Block:       sim.Genesis.Block,
+	}, true); err != nil {
+		tb.Fatal(err)
+	}
+	cm.AddSubscriber(e, cm.Tip())
+
+	wm, err := explorer.NewWebhookManager(explorerutil.NewEphemeralWebhookStore(), explorer.WithRetryBackoff(10*time.Millisecond))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { wm.Close() })
+	e.AddEventSubscriber(wm)
+
+	s := &stubSyncer{}
+	srv := httptest.NewServer(api.NewServer(cm, s, mp, e, wm))
+	tb.Cleanup(srv.Close)
+
+	return &testNode{
+		sim: sim,
+		cm:  cm,
+		tp:  tp,
+		e:   e,
+		s:   s,
+		c:   api.NewClient(srv.URL, ""),
+	}
+}
+
+func TestClient(t *testing.T) {
+	n := newTestNode(t)
+	w := walletutil.NewTestingWallet(n.cm.TipState())
+	n.cm.AddSubscriber(w, n.cm.Tip())
+
+	addr := w.NewAddress()
+	fund := n.sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(100), Address: addr})
+	if err := n.cm.AddTipBlock(fund); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range n.sim.MineBlocks(3) {
+		if err := n.cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tip := n.cm.Tip()
+
+	// chain
+	if stats, err := n.c.ChainStats(tip); err != nil {
+		t.Fatal(err)
+	} else if stats.Block.ID() != tip.ID || stats.SpentSiacoinsCount == 0 {
+		t.Fatal("wrong chain stats", stats)
+	}
+	if b, err := n.c.BlockByHeight(fund.Header.Height); err != nil {
+		t.Fatal(err)
+	} else if b.ID() != fund.ID() {
+		t.Fatal("wrong block by height")
+	}
+	if b, err := n.c.BlockByID(fund.ID()); err != nil {
+		t.Fatal(err)
+	} else if b.ID() != fund.ID() {
+		t.Fatal("wrong block by ID")
+	}
+
+	// address
+	if balance, err := n.c.AddressBalance(addr); err != nil {
+		t.Fatal(err)
+	} else if !balance.Siacoins.Equals(types.Siacoins(100)) || balance.Siafunds != 0 {
+		t.Fatal("wrong balance", balance)
+	} else if balance.SiacoinRank == 0 || balance.SiacoinPercentile == 0 || balance.SiafundRank != 0 {
+		t.Fatal("wrong rank", balance)
+	}
+	if list, err := n.c.SiacoinRichList("", 0); err != nil {
+		t.Fatal(err)
+	} else if len(list.Balances) == 0 || list.Balances[0].Rank != 1 {
+		t.Fatal("wrong rich list", list)
+	}
+	if sr, err := n.c.SiafundRevenue(addr); err != nil {
+		t.Fatal(err)
+	} else if sr.Siafunds != 0 || !sr.Claimed.IsZero() {
+		t.Fatal("wrong siafund revenue", sr)
+	}
+	if history, err := n.c.SiafundPoolHistory(0, tip.Height); err != nil {
+		t.Fatal(err)
+	} else if len(history) != int(tip.Height+1) || history[len(history)-1].Pool != n.cm.TipState().SiafundPool {
+		t.Fatal("wrong siafund pool history", history)
+	}
+	if fr, err := n.c.FoundationAddress(tip.Height); err != nil {
+		t.Fatal(err)
+	} else if fr.Height != tip.Height || fr.Address != n.cm.TipState().FoundationAddress {
+		t.Fatal("wrong Foundation address", fr)
+	} else if changes, err := n.c.FoundationChanges("", 0); err != nil {
+		t.Fatal(err)
+	} else if len(changes.Changes) != 0 {
+		t.Fatal("expected no Foundation changes", changes)
+	}
+	scos, err := n.c.SiacoinOutputs(addr, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(scos.IDs) != 1 || scos.NextCursor != "" {
+		t.Fatal("expected one siacoin output", scos)
+	}
+	if sfos, err := n.c.SiafundOutputs(addr, "", 0); err != nil {
+		t.Fatal(err)
+	} else if len(sfos.IDs) != 0 {
+		t.Fatal("expected no siafund outputs", sfos)
+	}
+	if elem, err := n.c.SiacoinElement(scos.IDs[0]); err != nil {
+		t.Fatal(err)
+	} else if elem.SiacoinElement.Address != addr || elem.Spent {
+		t.Fatal("wrong siacoin element", elem)
+	}
+
+	// batch
+	if resps, err := n.c.BatchSiacoins([]api.ExplorerAddressPageRequest{{Address: addr}, {Address: types.VoidAddress, Limit: 2}}); err != nil {
+		t.Fatal(err)
+	} else if len(resps) != 2 || len(resps[0].Elements) != 1 || resps[0].Elements[0].ID != scos.IDs[0] || resps[0].NextCursor != "" {
+		t.Fatal("wrong batch siacoins", resps)
+	} else if len(resps[1].Elements) != 2 || resps[1].NextCursor == "" {
+		t.Fatal("expected a partial page of void outputs", resps[1])
+	} else if next, err := n.c.BatchSiacoins([]api.ExplorerAddressPageRequest{{Address: types.VoidAddress, Cursor: resps[1].NextCursor, Limit: 2}}); err != nil {
+		t.Fatal(err)
+	} else if len(next) != 1 || len(next[0].Elements) == 0 || next[0].Elements[0].ID == resps[1].Elements[1].ID {
+		t.Fatal("wrong next page of batch siacoins", next)
+	}
+	if resps, err := n.c.BatchSiafunds([]api.ExplorerAddressPageRequest{{Address: addr}}); err != nil {
+		t.Fatal(err)
+	} else if len(resps) != 1 || len(resps[0].Elements) != 0 {
+		t.Fatal("wrong batch siafunds", resps)
+	}
+	if resps, err := n.c.BatchTransactions([]api.ExplorerAddressPageRequest{{Address: addr, Limit: 1}}); err != nil {
+		t.Fatal(err)
+	} else if len(resps) != 1 || len(resps[0].Transactions) != 1 || resps[0].Transactions[0].ID() != fund.Transactions[0].ID() || resps[0].NextCursor != "" {
+		t.Fatal("wrong batch transactions", resps)
+	}
+
+	// transaction
+	txid := fund.Transactions[0].ID()
+	if resp, err := n.c.Transactions(addr, "", 10); err != nil {
+		t.Fatal(err)
+	} else if len(resp.IDs) != 1 || resp.IDs[0] != txid {
+		t.Fatal("wrong address transactions", resp.IDs)
+	}
+	if txn, err := n.c.Transaction(txid); err != nil {
+		t.Fatal(err)
+	} else if txn.Transaction.ID() != txid || txn.BlockID != fund.ID() || txn.Confirmations != 4 {
+		t.Fatal("wrong transaction", txn)
+	}
+
+	// syncer
+	if err := n.c.SyncerConnect("1.2.3.4:9981"); err != nil {
+		t.Fatal(err)
+	} else if peers, err := n.c.SyncerPeers(); err != nil {
+		t.Fatal(err)
+	} else if len(peers) != 1 || peers[0].NetAddress != "1.2.3.4:9981" {
+		t.Fatal("wrong peers", peers)
+	}
+
+	// txpool
+	txn := types.Transaction{
+		SiacoinOutputs: []types.SiacoinOutput{{Address: types.VoidAddress, Value: types.Siacoins(1)}},
+	}
+	if err := w.FundAndSign(&txn); err != nil {
+		t.Fatal(err)
+	} else if err := n.c.TxpoolBroadcast(txn, nil); err != nil {
+		t.Fatal(err)
+	} else if txns, err := n.c.TxpoolTransactions(); err != nil {
+		t.Fatal(err)
+	} else if len(txns) != 1 || txns[0].ID != txn.ID() || txns[0].Size != uint64(types.EncodedLen(txn)) || txns[0].FirstSeen.IsZero() {
+		t.Fatal("wrong txpool transactions", txns)
+	} else if pa, err := n.c.PendingActivity(addr); err != nil {
+		t.Fatal(err)
+	} else if len(pa.Transactions) != 1 || pa.Transactions[0].ID != txn.ID() || pa.SiacoinsOut.IsZero() {
+		t.Fatal("wrong pending activity", pa)
+	} else if pa, err := n.c.PendingActivity(types.Address{1}); err != nil {
+		t.Fatal(err)
+	} else if len(pa.Transactions) != 0 {
+		t.Fatal("wrong pending activity", pa)
+	} else if fe, err := n.c.TxpoolFeeEstimate(); err != nil {
+		t.Fatal(err)
+	} else if fe.PoolTransactions != 1 || fe.PoolSize != uint64(types.EncodedLen(txn)) {
+		t.Fatal("wrong fee estimate", fe)
+	}
+	if stats, err := n.c.FeeStats(fund.Header.Height, tip.Height); err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 4 || stats[0].Transactions != uint64(len(fund.Transactions)) {
+		t.Fatal("wrong fee stats", stats)
+	}
+}
+
+func TestRoutePrefix(t *testing.T) {
+	n := newTestNode(t)
+	get := func(route string) int {
+		t.Helper()
+		resp, err := http.Get(n.c.BaseURL + route)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	route := "/explorer/block/0"
+
+	// routes are served beneath the versioned prefix, and beneath /api as an
+	// alias for the latest version
+	if code := get("/api/" + api.APIVersion + route); code != http.StatusOK {
+		t.Fatal("expected versioned route to succeed, got", code)
+	} else if code := get("/api" + route); code != http.StatusOK {
+		t.Fatal("expected unversioned route to succeed, got", code)
+	}
+	// routes are only served beneath /api
+	if code := get(route); code != http.StatusNotFound {
+		t.Fatal("expected unprefixed route to 404, got", code)
+	} else if code := get("/api/v0" + route); code != http.StatusNotFound {
+		t.Fatal("expected unknown version to 404, got", code)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	n := newTestNode(t)
+
+	// a missing object should be reported as not found
+	_, err := n.c.BlockByHeight(100)
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+	_, err = n.c.Transaction(types.TransactionID{1})
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+
+	// a malformed parameter should be reported as a bad request, naming the
+	// parameter
+	_, err = n.c.ElementTrace(types.ElementID{}, true, 1000)
+	var apiErr *api.Error
+	if !errors.Is(err, api.ErrBadRequest) || !errors.As(err, &apiErr) {
+		t.Fatal("expected ErrBadRequest, got", err)
+	} else if details, ok := apiErr.Details.(map[string]interface{}); !ok || details["param"] != "depth" {
+		t.Fatal("wrong error details", apiErr.Details)
+	}
+
+	// heights beyond those the store can hold are rejected, and the default
+	// end of the expiry window is clamped rather than overflowing
+	if _, err := n.c.ContractsExpiring(0, math.MaxUint64); !errors.Is(err, api.ErrBadRequest) {
+		t.Fatal("expected ErrBadRequest, got", err)
+	}
+	resp, err := http.Get(fmt.Sprintf("%v/api/v1/explorer/contracts/expiring?from=%d", n.c.BaseURL, uint64(math.MaxInt64)-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("expected clamped window to succeed, got", resp.Status)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	n := newTestNode(t)
+	addr := types.Address{1, 2, 3}
+	b := n.sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(1), Address: addr})
+	if err := n.cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	txn := b.Transactions[0]
+	eid := txn.SiacoinOutputID(0)
+	host := n.sim.Genesis.Block.Transactions[0].FileContracts[0].HostPublicKey
+
+	tests := []struct {
+		query string
+		typ   string
+		id    string
+	}{
+		{fmt.Sprint(b.Header.Height), api.SearchResultBlock, b.ID().String()},
+		{b.ID().String(), api.SearchResultBlock, b.ID().String()},
+		{txn.ID().String(), api.SearchResultTransaction, txn.ID().String()},
+		{strings.TrimPrefix(txn.ID().String(), "txid:"), api.SearchResultTransaction, txn.ID().String()},
+		{eid.String(), api.SearchResultSiacoinElement, eid.String()},
+		{addr.String(), api.SearchResultAddress, addr.String()},
+		{strings.TrimPrefix(addr.String(), "addr:"), api.SearchResultAddress, addr.String()},
+		// the simulator's miner payouts are its only transfers to the void
+		{types.VoidAddress.String(), api.SearchResultAddress, types.VoidAddress.String()},
+		{host.String(), api.SearchResultHost, host.String()},
+	}
+	for _, test := range tests {
+		results, err := n.c.Search(test.query)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(results) != 1 || results[0].Type != test.typ || results[0].ID != test.id {
+			t.Fatalf("wrong results for %q: %v", test.query, results)
+		}
+	}
+
+	for _, query := range []string{"100", types.TransactionID{1}.String(), types.Address{4}.String(), strings.Repeat("ab", 64), "foo"} {
+		if _, err := n.c.Search(query); !errors.Is(err, api.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound for %q, got %v", query, err)
+		}
+	}
+	if _, err := n.c.ElementSearch(types.ElementID{Index: 1000}); !errors.Is(err, api.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+}
+
+func nextEvent(tb testing.TB, es *api.EventStream) explorer.Event {
+	type result struct {
+		ev  explorer.Event
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		ev, err := es.Next()
+		ch <- result{ev, err}
+	}()
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			tb.Fatal(r.err)
+		}
+		return r.ev
+	case <-time.After(5 * time.Second):
+		tb.Fatal("timed out waiting for event")
+	}
+	panic("unreachable")
+}
+
+func TestEvents(t *testing.T) {
+	n := newTestNode(t)
+	w := walletutil.NewTestingWallet(n.cm.TipState())
+	n.cm.AddSubscriber(w, n.cm.Tip())
+	addr := w.NewAddress()
+	start := n.cm.Tip()
+
+	es, err := n.c.Events(nil, []types.Address{addr}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer es.Close()
+
+	// a block paying our address should produce a transaction event followed
+	// by a block event
+	fund := n.sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(100), Address: addr})
+	if err := n.cm.AddTipBlock(fund); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, es); ev.Type != explorer.EventTransactionApplied || ev.Transaction != fund.Transactions[0].ID() || ev.Index != fund.Index() {
+		t.Fatal("expected transaction event", ev)
+	} else if ev := nextEvent(t, es); ev.Type != explorer.EventBlockApplied || ev.Tip != fund.Index() {
+		t.Fatal("expected block event", ev)
+	}
+
+	// an unrelated block should only produce a block event
+	b := n.sim.MineBlock()
+	if err := n.cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	} else if ev := nextEvent(t, es); ev.Type != explorer.EventBlockApplied || ev.Index != b.Index() {
+		t.Fatal("expected block event", ev)
+	}
+
+	// transactions entering the pool should be reported
+	txn := types.Transaction{
+		SiacoinOutputs: []types.SiacoinOutput{{Address: types.VoidAddress, Value: types.Siacoins(1)}},
+	}
+	if err := w.FundAndSign(&txn); err != nil {
+		t.Fatal(err)
+	} else if err := n.c.TxpoolBroadcast(txn, nil); err != nil {
+		t.Fatal(err)
+	} else if ev := nextEvent(t, es); ev.Type != explorer.EventTransactionPooled || ev.Transaction != txn.ID() {
+		t.Fatal("expected pooled transaction event", ev)
+	}
+
+	// resuming from an earlier index should replay the blocks since then
+	resumed, err := n.c.Events(&start, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+	for _, b := range []types.Block{fund, b} {
+		for range b.Transactions {
+			if ev := nextEvent(t, resumed); ev.Type != explorer.EventTransactionApplied {
+				t.Fatal("expected transaction event", ev)
+			}
+		}
+		if ev := nextEvent(t, resumed); ev.Type != explorer.EventBlockApplied || ev.Index != b.Index() {
+			t.Fatal("expected block event", ev)
+		}
+	}
+
+	// resuming from an unknown index should fail
+	if _, err := n.c.Events(&types.ChainIndex{Height: 1}, nil, nil); !errors.Is(err, api.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+}
+
+func TestEventsReorg(t *testing.T) {
+	n := newTestNode(t)
+	if err := n.cm.AddTipBlock(n.sim.MineBlock()); err != nil {
+		t.Fatal(err)
+	}
+	fork := n.sim.Fork()
+	orphans := n.sim.MineBlocks(2)
+	for _, b := range orphans {
+		if err := n.cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := n.cm.Tip()
+
+	// reorg to a better chain while the client is disconnected
+	better := fork.MineBlocks(4)
+	chainutil.FindBlockNonce(&better[3].Header, types.HashRequiringWork(n.sim.State.TotalWork))
+	if _, err := n.cm.AddHeaders(chainutil.JustHeaders(better)); err != nil {
+		t.Fatal(err)
+	} else if _, err := n.cm.AddBlocks(better); err != nil {
+		t.Fatal(err)
+	} else if n.cm.Tip() != better[3].Index() {
+		t.Fatal("didn't reorg to better chain")
+	}
+
+	// resuming from the orphaned tip should revert the orphans, newest first,
+	// then apply the better chain
+	es, err := n.c.Events(&since, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer es.Close()
+	nextBlockEvent := func() explorer.Event {
+		for {
+			if ev := nextEvent(t, es); ev.Type == explorer.EventBlockApplied || ev.Type == explorer.EventBlockReverted {
+				return ev
+			}
+		}
+	}
+	for i := len(orphans) - 1; i >= 0; i-- {
+		if ev := nextBlockEvent(); ev.Type != explorer.EventBlockReverted || ev.Index != orphans[i].Index() {
+			t.Fatal("expected block reverted event", ev)
+		}
+	}
+	for _, b := range better {
+		if ev := nextBlockEvent(); ev.Type != explorer.EventBlockApplied || ev.Index != b.Index() {
+			t.Fatal("expected block applied event", ev)
+		}
+	}
+}
+
+func TestEventsCatchUp(t *testing.T) {
+	sim := chainutil.NewChainSim()
+	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.State)
+	tp := txpool.New(sim.Genesis.State)
+	cm.AddSubscriber(tp, cm.Tip())
+	mp := explorer.NewMempool(tp)
+	cm.AddSubscriber(mp, cm.Tip())
+	defer mp.Close()
+	hs, err := explorerutil.NewHashStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{
+		ApplyUpdate: consensus.GenesisUpdate(sim.Genesis.Block, types.Work{NumHashes: [32]byte{31: 4}}),
+		Block:       sim.Genesis.Block,
+	}, true); err != nil {
+		t.Fatal(err)
+	}
+	wm, err := explorer.NewWebhookManager(explorerutil.NewEphemeralWebhookStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wm.Close()
+	srv := httptest.NewServer(api.NewServer(cm, &stubSyncer{}, mp, e, wm))
+	defer srv.Close()
+	c := api.NewClient(srv.URL, "")
+
+	// the chain manager is ahead of the explorer; resuming from its tip should
+	// wait for the explorer rather than reverting the tip
+	for _, b := range sim.MineBlocks(2) {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := cm.Tip()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		if err := cm.AddSubscriber(e, sim.Genesis.State.Index); err != nil {
+			t.Error(err)
+		}
+	}()
+	es, err := c.Events(&since, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer es.Close()
+	b := sim.MineBlock()
+	if err := cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		ev := nextEvent(t, es)
+		if ev.Type == explorer.EventBlockReverted {
+			t.Fatal("unexpected block reverted event", ev)
+		} else if ev.Type == explorer.EventBlockApplied {
+			if ev.Index != b.Index() {
+				t.Fatal("expected block applied event for the new block", ev)
+			}
+			break
+		}
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	n := newTestNode(t)
+
+	// the receiver rejects the first delivery, forcing a retry
+	var secret string
+	var attempts int
+	notifications := make(chan explorer.WebhookNotification, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		if req.Header.Get(explorer.WebhookSignatureHeader) != explorer.SignWebhook(secret, body) {
+			t.Error("bad signature")
+		}
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var wn explorer.WebhookNotification
+		if err := json.Unmarshal(body, &wn); err != nil {
+			t.Error(err)
+		}
+		notifications <- wn
+	}))
+	defer receiver.Close()
+	next := func() explorer.WebhookNotification {
+		select {
+		case wn := <-notifications:
+			return wn
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+		panic("unreachable")
+	}
+
+	if _, err := n.c.AddWebhook(api.ExplorerWebhookRequest{URL: "not a url", Addresses: []types.Address{{1}}}); !errors.Is(err, api.ErrBadRequest) {
+		t.Fatal("expected ErrBadRequest, got", err)
+	}
+	pubkey, _ := testingKeypair(1)
+	addr := types.StandardAddress(pubkey)
+	wh, err := n.c.AddWebhook(api.ExplorerWebhookRequest{URL: receiver.URL, Addresses: []types.Address{addr}})
+	if err != nil {
+		t.Fatal(err)
+	} else if wh.Secret == "" {
+		t.Fatal("expected a generated secret")
+	}
+	secret = wh.Secret
+	if hooks, err := n.c.Webhooks(); err != nil {
+		t.Fatal(err)
+	} else if len(hooks) != 1 || hooks[0].ID != wh.ID || hooks[0].Secret != "" {
+		t.Fatal("wrong webhooks", hooks)
+	}
+
+	// paying the address should trigger a notification
+	fork := n.sim.Fork()
+	fund := n.sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(1), Address: addr})
+	if err := n.cm.AddTipBlock(fund); err != nil {
+		t.Fatal(err)
+	}
+	if wn := next(); wn.Webhook != wh.ID || wn.Reverted || wn.Transaction != fund.Transactions[0].ID() || wn.Index != fund.Index() {
+		t.Fatal("wrong notification", wn)
+	} else if attempts != 2 {
+		t.Fatal("expected delivery to be retried", attempts)
+	}
+
+	// reorg the payment out
+	betterChain := fork.MineBlocks(2)
+	chainutil.FindBlockNonce(&betterChain[1].Header, types.HashRequiringWork(n.sim.State.TotalWork))
+	if _, err := n.cm.AddHeaders(chainutil.JustHeaders(betterChain)); err != nil {
+		t.Fatal(err)
+	} else if _, err := n.cm.AddBlocks(betterChain); err != nil {
+		t.Fatal(err)
+	}
+	if wn := next(); !wn.Reverted || wn.Transaction != fund.Transactions[0].ID() {
+		t.Fatal("expected reverted notification", wn)
+	}
+
+	// so should mining a block paying the address
+	prev := betterChain[len(betterChain)-1]
+	payout := types.Block{
+		Header: types.BlockHeader{
+			Height:       prev.Header.Height + 1,
+			ParentID:     prev.ID(),
+			Timestamp:    prev.Header.Timestamp.Add(time.Second),
+			MinerAddress: addr,
+		},
+	}
+	payout.Header.Commitment = fork.State.Commitment(addr, nil)
+	chainutil.FindBlockNonce(&payout.Header, types.HashRequiringWork(fork.State.Difficulty))
+	if err := n.cm.AddTipBlock(payout); err != nil {
+		t.Fatal(err)
+	}
+	if wn := next(); wn.Reverted || wn.Transaction != (types.TransactionID{}) || wn.Index != payout.Index() ||
+		len(wn.Addresses) != 1 || wn.Addresses[0] != addr || len(wn.Elements) != 1 || wn.Elements[0] != payout.MinerOutputID() {
+		t.Fatal("expected payout notification", wn)
+	}
+
+	if err := n.c.RemoveWebhook(wh.ID); err != nil {
+		t.Fatal(err)
+	} else if err := n.c.RemoveWebhook(wh.ID); !errors.Is(err, api.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	} else if hooks, err := n.c.Webhooks(); err != nil {
+		t.Fatal(err)
+	} else if len(hooks) != 0 {
+		t.Fatal("expected no webhooks", hooks)
+	}
+}
@@ -0,0 +1,61 @@
+package explorer
+
+import (
+	"go.sia.tech/core/types"
+)
+
+// An Attestation is an attestation along with the transaction that carried it.
+type Attestation struct {
+	Attestation types.Attestation
+	Index       types.ChainIndex
+	Transaction types.TransactionID
+}
+
+// EncodeTo implements types.EncoderTo.
+func (a Attestation) EncodeTo(e *types.Encoder) {
+	a.Attestation.EncodeTo(e)
+	a.Index.EncodeTo(e)
+	a.Transaction.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (a *Attestation) DecodeFrom(d *types.Decoder) {
+	a.Attestation.DecodeFrom(d)
+	a.Index.DecodeFrom(d)
+	a.Transaction.DecodeFrom(d)
+}
+
+// blockAttestations returns the attestations in b, in the order in which they
+// appear.
+func blockAttestations(b types.Block) []Attestation {
+	index := b.Index()
+	var atts []Attestation
+	for _, txn := range b.Transactions {
+		txid := txn.ID()
+		for _, a := range txn.Attestations {
+			atts = append(atts, Attestation{
+				Attestation: a,
+				Index:       index,
+				Transaction: txid,
+			})
+		}
+	}
+	return atts
+}
+
+// Attestations returns up to limit attestations made by the specified public
+// key, newest first, starting at cursor. If key is non-empty, only
+// attestations with that key are returned.
+func (e *Explorer) Attestations(pubkey types.PublicKey, key string, cursor Cursor, limit int) ([]Attestation, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.Attestations(pubkey, key, cursor, limit)
+}
+
+// LatestAttestations returns the most recent attestation made by the
+// specified public key for each key, ordered by key.
+func (e *Explorer) LatestAttestations(pubkey types.PublicKey) ([]Attestation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.LatestAttestations(pubkey)
+}
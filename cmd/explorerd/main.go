@@ -83,9 +83,11 @@ func main() {
 		log.Fatal(err)
 	}
 	log.Println("api: Listening on", l.Addr())
+	mux := http.NewServeMux()
+	mux.Handle("/api/", api.NewServer(n.c, n.s, n.mp, n.e, n.wm))
+	// TODO: serve the UI and other static content on "/"
 	go func() {
-		// TODO: if we add a UI, serve the API on /api
-		if err := http.Serve(l, api.NewServer(n.c, n.s, n.tp, n.e)); err != nil {
+		if err := http.Serve(l, mux); err != nil {
 			log.Println(err)
 		}
 	}()
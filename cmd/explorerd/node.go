@@ -17,7 +17,9 @@ import (
 type node struct {
 	c  *chain.Manager
 	tp *txpool.Pool
+	mp *explorer.Mempool
 	e  *explorer.Explorer
+	wm *explorer.WebhookManager
 	s  *p2p.Syncer
 }
 
@@ -28,6 +30,8 @@ func (n *node) run() error {
 func (n *node) Close() error {
 	errs := []error{
 		n.s.Close(),
+		n.mp.Close(),
+		n.wm.Close(),
 		n.c.Close(),
 	}
 	for _, err := range errs {
@@ -51,6 +55,8 @@ func newNode(addr, dir string, c consensus.Checkpoint) (*node, error) {
 	cm := chain.NewManager(chainStore, tip.State)
 	tp := txpool.New(tip.State)
 	cm.AddSubscriber(tp, cm.Tip())
+	mp := explorer.NewMempool(tp)
+	cm.AddSubscriber(mp, cm.Tip())
 
 	explorerDir := filepath.Join(dir, "explorer")
 	if err := os.MkdirAll(explorerDir, 0700); err != nil {
@@ -68,9 +74,22 @@ func newNode(addr, dir string, c consensus.Checkpoint) (*node, error) {
 	if err != nil {
 		return nil, err
 	}
-	e := explorer.NewExplorer(tip.State, store, hs)
+	e, err := explorer.NewExplorer(tip.State, store, hs)
+	if err != nil {
+		return nil, err
+	}
 	cm.AddSubscriber(e, tip.State.Index)
 
+	webhookStore, err := explorerutil.NewWebhookStore(filepath.Join(explorerDir, "webhooks.db"))
+	if err != nil {
+		return nil, err
+	}
+	wm, err := explorer.NewWebhookManager(webhookStore)
+	if err != nil {
+		return nil, err
+	}
+	e.AddEventSubscriber(wm)
+
 	p2pDir := filepath.Join(dir, "p2p")
 	if err := os.MkdirAll(p2pDir, 0700); err != nil {
 		return nil, err
@@ -87,7 +106,9 @@ func newNode(addr, dir string, c consensus.Checkpoint) (*node, error) {
 	return &node{
 		c:  cm,
 		tp: tp,
+		mp: mp,
 		e:  e,
+		wm: wm,
 		s:  s,
 	}, nil
 }
@@ -0,0 +1,313 @@
+package explorer
+
+import (
+	"go.sia.tech/core/types"
+)
+
+// Contract event types.
+const (
+	ContractEventFormation  = "formation"
+	ContractEventRevision   = "revision"
+	ContractEventResolution = "resolution"
+)
+
+// Contract statuses.
+const (
+	ContractStatusActive   = "active"
+	ContractStatusResolved = "resolved"
+	ContractStatusMissed   = "missed"
+)
+
+// A ContractEvent is a step in the lifecycle of a file contract. Contract is
+// the state of the contract after the event. For resolutions, Valid reports
+// whether the host fulfilled the contract, and the payouts are the outputs
+// created by the resolution.
+type ContractEvent struct {
+	Type        string
+	ContractID  types.ElementID
+	Index       types.ChainIndex
+	Transaction types.TransactionID
+	Contract    types.FileContract
+
+	Valid        bool
+	RenterPayout types.SiacoinOutput
+	HostPayout   types.SiacoinOutput
+}
+
+// EncodeTo implements types.EncoderTo.
+func (ce ContractEvent) EncodeTo(e *types.Encoder) {
+	e.WriteString(ce.Type)
+	ce.ContractID.EncodeTo(e)
+	ce.Index.EncodeTo(e)
+	ce.Transaction.EncodeTo(e)
+	ce.Contract.EncodeTo(e)
+	e.WriteBool(ce.Valid)
+	ce.RenterPayout.EncodeTo(e)
+	ce.HostPayout.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (ce *ContractEvent) DecodeFrom(d *types.Decoder) {
+	ce.Type = d.ReadString()
+	ce.ContractID.DecodeFrom(d)
+	ce.Index.DecodeFrom(d)
+	ce.Transaction.DecodeFrom(d)
+	ce.Contract.DecodeFrom(d)
+	ce.Valid = d.ReadBool()
+	ce.RenterPayout.DecodeFrom(d)
+	ce.HostPayout.DecodeFrom(d)
+}
+
+// resolveContract returns the final state of the contract resolved by fcr,
+// whether the resolution is valid, and the resulting payouts, mirroring the
+// outputs created by consensus.
+func resolveContract(fcr types.FileContractResolution) (fc types.FileContract, valid bool, renter, host types.SiacoinOutput) {
+	fc = fcr.Parent.FileContract
+	switch {
+	case fcr.HasRenewal():
+		fc = fcr.Renewal.FinalRevision
+		renter, host = fc.RenterOutput, fc.HostOutput
+		renter.Value = renter.Value.Sub(fcr.Renewal.RenterRollover)
+		host.Value = host.Value.Sub(fcr.Renewal.HostRollover)
+		return fc, true, renter, host
+	case fcr.HasStorageProof():
+		return fc, true, fc.RenterOutput, fc.HostOutput
+	case fcr.HasFinalization():
+		fc = fcr.Finalization
+		return fc, true, fc.RenterOutput, fc.HostOutput
+	case fc.Filesize == 0:
+		return fc, true, fc.RenterOutput, fc.HostOutput
+	default:
+		return fc, false, fc.RenterOutput, fc.MissedHostOutput()
+	}
+}
+
+// contractEvents returns the contract events in b, in the order in which they
+// occurred. newContracts are the contracts created by b.
+func contractEvents(b types.Block, newContracts []types.FileContractElement) []ContractEvent {
+	index := b.Index()
+	formed := make(map[types.TransactionID][]types.FileContractElement)
+	for _, fce := range newContracts {
+		txid := types.TransactionID(fce.ID.Source)
+		formed[txid] = append(formed[txid], fce)
+	}
+
+	var events []ContractEvent
+	for _, txn := range b.Transactions {
+		txid := txn.ID()
+		for _, fcr := range txn.FileContractRevisions {
+			events = append(events, ContractEvent{
+				Type:        ContractEventRevision,
+				ContractID:  fcr.Parent.ID,
+				Index:       index,
+				Transaction: txid,
+				Contract:    fcr.Revision,
+			})
+		}
+		for _, fcr := range txn.FileContractResolutions {
+			fc, valid, renter, host := resolveContract(fcr)
+			events = append(events, ContractEvent{
+				Type:         ContractEventResolution,
+				ContractID:   fcr.Parent.ID,
+				Index:        index,
+				Transaction:  txid,
+				Contract:     fc,
+				Valid:        valid,
+				RenterPayout: renter,
+				HostPayout:   host,
+			})
+		}
+		// renewals form new contracts
+		for _, fce := range formed[txid] {
+			events = append(events, ContractEvent{
+				Type:        ContractEventFormation,
+				ContractID:  fce.ID,
+				Index:       index,
+				Transaction: txid,
+				Contract:    fce.FileContract,
+			})
+		}
+	}
+	return events
+}
+
+// HostStats contains aggregate information about the contracts formed with a
+// host. ValidResolutions includes renewals, finalizations, and expirations of
+// empty contracts as well as storage proofs, which are counted separately in
+// StorageProofs.
+type HostStats struct {
+	Contracts         uint64
+	ActiveContracts   uint64
+	ValidResolutions  uint64
+	MissedResolutions uint64
+	StorageProofs     uint64
+	StoredBytes       uint64         // total filesize of active contracts
+	LockedCollateral  types.Currency // total collateral of active contracts
+}
+
+// ProofSuccessRate returns the fraction of the host's required storage proofs
+// that it submitted, or 0 if none were required. Contracts resolved without a
+// proof being due, such as renewals and finalizations, are not counted.
+func (hs HostStats) ProofSuccessRate() float64 {
+	due := hs.StorageProofs + hs.MissedResolutions
+	if due == 0 {
+		return 0
+	}
+	return float64(hs.StorageProofs) / float64(due)
+}
+
+// EncodeTo implements types.EncoderTo.
+func (hs HostStats) EncodeTo(e *types.Encoder) {
+	e.WriteUint64(hs.Contracts)
+	e.WriteUint64(hs.ActiveContracts)
+	e.WriteUint64(hs.ValidResolutions)
+	e.WriteUint64(hs.MissedResolutions)
+	e.WriteUint64(hs.StorageProofs)
+	e.WriteUint64(hs.StoredBytes)
+	hs.LockedCollateral.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (hs *HostStats) DecodeFrom(d *types.Decoder) {
+	hs.Contracts = d.ReadUint64()
+	hs.ActiveContracts = d.ReadUint64()
+	hs.ValidResolutions = d.ReadUint64()
+	hs.MissedResolutions = d.ReadUint64()
+	hs.StorageProofs = d.ReadUint64()
+	hs.StoredBytes = d.ReadUint64()
+	hs.LockedCollateral.DecodeFrom(d)
+}
+
+// A hostDelta records the changes a block made to a host's stats. Additions
+// and removals are tracked separately so that the delta can be applied and
+// reverted without underflow.
+type hostDelta struct {
+	formed, valid, missed, proofs    uint64
+	bytesAdded, bytesRemoved         uint64
+	collateralAdded, collateralFreed types.Currency
+}
+
+func (d hostDelta) apply(hs HostStats) HostStats {
+	hs.Contracts += d.formed
+	hs.ActiveContracts = hs.ActiveContracts + d.formed - d.valid - d.missed
+	hs.ValidResolutions += d.valid
+	hs.MissedResolutions += d.missed
+	hs.StorageProofs += d.proofs
+	hs.StoredBytes = hs.StoredBytes + d.bytesAdded - d.bytesRemoved
+	hs.LockedCollateral = hs.LockedCollateral.Add(d.collateralAdded).Sub(d.collateralFreed)
+	return hs
+}
+
+func (d hostDelta) revert(hs HostStats) HostStats {
+	hs.Contracts -= d.formed
+	hs.ActiveContracts = hs.ActiveContracts + d.valid + d.missed - d.formed
+	hs.ValidResolutions -= d.valid
+	hs.MissedResolutions -= d.missed
+	hs.StorageProofs -= d.proofs
+	hs.StoredBytes = hs.StoredBytes + d.bytesRemoved - d.bytesAdded
+	hs.LockedCollateral = hs.LockedCollateral.Add(d.collateralFreed).Sub(d.collateralAdded)
+	return hs
+}
+
+// hostDeltas returns the changes to host stats caused by b. newContracts are
+// the contracts created by b.
+func hostDeltas(b types.Block, newContracts []types.FileContractElement) map[types.PublicKey]hostDelta {
+	deltas := make(map[types.PublicKey]hostDelta)
+	for _, fce := range newContracts {
+		fc := fce.FileContract
+		d := deltas[fc.HostPublicKey]
+		d.formed++
+		d.bytesAdded += fc.Filesize
+		d.collateralAdded = d.collateralAdded.Add(fc.TotalCollateral)
+		deltas[fc.HostPublicKey] = d
+	}
+	for _, txn := range b.Transactions {
+		for _, fcr := range txn.FileContractRevisions {
+			parent := fcr.Parent.FileContract
+			d := deltas[parent.HostPublicKey]
+			d.bytesAdded += fcr.Revision.Filesize
+			d.bytesRemoved += parent.Filesize
+			deltas[parent.HostPublicKey] = d
+		}
+		for _, fcr := range txn.FileContractResolutions {
+			parent := fcr.Parent.FileContract
+			d := deltas[parent.HostPublicKey]
+			if _, valid, _, _ := resolveContract(fcr); valid {
+				d.valid++
+			} else {
+				d.missed++
+			}
+			if fcr.HasStorageProof() {
+				d.proofs++
+			}
+			d.bytesRemoved += parent.Filesize
+			d.collateralFreed = d.collateralFreed.Add(parent.TotalCollateral)
+			deltas[parent.HostPublicKey] = d
+		}
+	}
+	return deltas
+}
+
+// HostContracts returns up to limit IDs of contracts formed with the specified
+// host, newest first, starting at cursor. If status is non-empty, only
+// contracts with that status are returned.
+func (e *Explorer) HostContracts(pubkey types.PublicKey, status string, cursor Cursor, limit int) ([]types.ElementID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.HostContracts(pubkey, status, cursor, limit)
+}
+
+// RenterContracts returns up to limit IDs of contracts formed by the specified
+// renter, newest first, starting at cursor. If status is non-empty, only
+// contracts with that status are returned.
+func (e *Explorer) RenterContracts(pubkey types.PublicKey, status string, cursor Cursor, limit int) ([]types.ElementID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.RenterContracts(pubkey, status, cursor, limit)
+}
+
+// HostStats returns aggregate information about the contracts formed with the
+// specified host.
+func (e *Explorer) HostStats(pubkey types.PublicKey) (HostStats, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	hs, err := e.db.HostStats(pubkey)
+	if err != nil {
+		return HostStats{}, err
+	} else if hs.Contracts == 0 {
+		return HostStats{}, ErrNotFound
+	}
+	return hs, nil
+}
+
+// ContractsExpiring returns the active contracts whose proof window opens or
+// closes at a height in [from, to], ordered by the start of their window.
+func (e *Explorer) ContractsExpiring(from, to uint64) ([]types.FileContractElement, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids, err := e.db.ContractsExpiring(from, to)
+	if err != nil {
+		return nil, err
+	}
+	fces := make([]types.FileContractElement, len(ids))
+	for i, id := range ids {
+		if fces[i], err = e.db.FileContractElement(id); err != nil {
+			return nil, err
+		}
+	}
+	return fces, nil
+}
+
+// ContractHistory returns the lifecycle of the file contract with the
+// specified ID, oldest first.
+func (e *Explorer) ContractHistory(id types.ElementID) ([]ContractEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events, err := e.db.ContractHistory(id)
+	if err != nil {
+		return nil, err
+	} else if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return events, nil
+}
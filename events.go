@@ -0,0 +1,170 @@
+package explorer
+
+import (
+	"errors"
+
+	"go.sia.tech/core/types"
+)
+
+// Event types.
+const (
+	EventBlockApplied        = "blockApplied"
+	EventBlockReverted       = "blockReverted"
+	EventTransactionApplied  = "transactionApplied"
+	EventTransactionReverted = "transactionReverted"
+	// EventTransactionPooled and EventTransactionRemoved are not published
+	// by the Explorer itself; a Mempool publishes them when a transaction is
+	// added to or removed from its pool, whether by confirmation or eviction.
+	EventTransactionPooled  = "transactionPooled"
+	EventTransactionRemoved = "transactionRemoved"
+)
+
+// An Event describes a change observed by the explorer.
+//
+// Block events describe the block at Index; Tip is the explorer's tip after
+// the event, i.e. the block itself when applied and its parent when reverted.
+// They list the addresses and IDs of the siacoin elements the block created
+// outside of any transaction, i.e. its miner payout and Foundation subsidy.
+// Transaction events list the addresses and elements the transaction touched,
+// including the recipients of any contract or siafund claim payouts, so that
+// they can be filtered; Index is the block containing the transaction, and is
+// zero for pooled transactions.
+type Event struct {
+	Type        string
+	Index       types.ChainIndex
+	Tip         types.ChainIndex
+	Transaction types.TransactionID
+	Addresses   []types.Address
+	Elements    []types.ElementID
+}
+
+// An EventSubscriber is notified of each Event published by an Explorer or
+// Mempool. ProcessEvent is called with the publisher locked, so it must not
+// block.
+type EventSubscriber interface {
+	ProcessEvent(ev Event)
+}
+
+// TransactionEvent returns an Event of the given type describing txn. Its
+// Elements are those txn consumed, created, or revised.
+func TransactionEvent(typ string, index types.ChainIndex, txn types.Transaction) Event {
+	elements := append(transactionInputs(txn), transactionOutputs(txn)...)
+	for _, fcr := range txn.FileContractRevisions {
+		elements = append(elements, fcr.Parent.ID)
+	}
+	return Event{
+		Type:        typ,
+		Index:       index,
+		Transaction: txn.ID(),
+		Addresses:   transactionAddresses(txn),
+		Elements:    elements,
+	}
+}
+
+// blockEvents returns the events describing the application or reversion of
+// b, which created the siacoin elements newSC. Transaction events precede the
+// block event, so that a subscriber that resumes from the block event's Tip
+// has seen the whole block.
+func blockEvents(b types.Block, newSC []types.SiacoinElement, reverted bool) []Event {
+	index := b.Index()
+	txnEvents := make([]Event, len(b.Transactions))
+	byID := make(map[types.TransactionID]*Event, len(b.Transactions))
+	for i, txn := range b.Transactions {
+		typ := EventTransactionApplied
+		if reverted {
+			typ = EventTransactionReverted
+		}
+		txnEvents[i] = TransactionEvent(typ, index, txn)
+		byID[txnEvents[i].Transaction] = &txnEvents[i]
+	}
+	blockEvent := Event{Type: EventBlockApplied, Index: index, Tip: index}
+	if reverted {
+		parent := types.ChainIndex{Height: index.Height - 1, ID: b.Header.ParentID}
+		blockEvent = Event{Type: EventBlockReverted, Index: index, Tip: parent}
+	}
+
+	// outputs created by transactions (including contract and claim payouts)
+	// are attributed to their transaction; the rest belong to the block
+	addAddress := func(ev *Event, addr types.Address) {
+		for _, a := range ev.Addresses {
+			if a == addr {
+				return
+			}
+		}
+		ev.Addresses = append(ev.Addresses, addr)
+	}
+	for _, sce := range newSC {
+		if ev, ok := byID[types.TransactionID(sce.ID.Source)]; ok {
+			addAddress(ev, sce.Address)
+		} else {
+			addAddress(&blockEvent, sce.Address)
+			blockEvent.Elements = append(blockEvent.Elements, sce.ID)
+		}
+	}
+
+	if reverted {
+		for i, j := 0, len(txnEvents)-1; i < j; i, j = i+1, j-1 {
+			txnEvents[i], txnEvents[j] = txnEvents[j], txnEvents[i]
+		}
+	}
+	return append(txnEvents, blockEvent)
+}
+
+// RevertedBlockEvents returns the events that are published when b, which
+// created the siacoin elements newSC, is reverted.
+func RevertedBlockEvents(b types.Block, newSC []types.SiacoinElement) []Event {
+	return blockEvents(b, newSC, true)
+}
+
+func (e *Explorer) publish(events []Event) {
+	for s := range e.subscribers {
+		for _, ev := range events {
+			s.ProcessEvent(ev)
+		}
+	}
+}
+
+// AddEventSubscriber subscribes s to the events published by the explorer,
+// returning the index of the latest block processed. s will receive events
+// for every subsequent block.
+func (e *Explorer) AddEventSubscriber(s EventSubscriber) types.ChainIndex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subscribers[s] = struct{}{}
+	return e.cs.Index
+}
+
+// RemoveEventSubscriber unsubscribes s from the events published by the
+// explorer.
+func (e *Explorer) RemoveEventSubscriber(s EventSubscriber) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.subscribers, s)
+}
+
+// BlockEvents returns the events that were published when the block at the
+// specified height in the best chain was applied.
+func (e *Explorer) BlockEvents(height uint64) ([]Event, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, err := e.db.BlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	// recover the siacoin elements the block created from the candidate IDs
+	ids := []types.ElementID{b.MinerOutputID(), b.FoundationOutputID()}
+	for _, txn := range b.Transactions {
+		ids = append(ids, transactionOutputs(txn)...)
+	}
+	var newSC []types.SiacoinElement
+	for _, id := range ids {
+		sce, err := e.db.SiacoinElement(id)
+		if errors.Is(err, ErrNotFound) {
+			continue // not a siacoin element
+		} else if err != nil {
+			return nil, err
+		}
+		newSC = append(newSC, sce)
+	}
+	return blockEvents(b, newSC, false), nil
+}
@@ -1,6 +1,7 @@
 package explorer
 
 import (
+	"errors"
 	"sync"
 
 	"go.sia.tech/core/chain"
@@ -8,30 +9,106 @@ import (
 	"go.sia.tech/core/types"
 )
 
+var (
+	// ErrNotFound is returned by a Store when the requested object does not
+	// exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnavailable is returned by a Store when it is temporarily unable to
+	// service a request, e.g. because the database is locked.
+	ErrUnavailable = errors.New("store unavailable")
+
+	// ErrInvalidCursor is returned by a Store when a Cursor is malformed.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
 // A Store is a database that stores information about elements, contracts,
 // and blocks.
 type Store interface {
 	ChainStats(index types.ChainIndex) (ChainStats, error)
+	BlockByHeight(height uint64) (types.Block, error)
+	BlockByID(id types.BlockID) (types.Block, error)
 	SiacoinElement(id types.ElementID) (types.SiacoinElement, error)
 	SiafundElement(id types.ElementID) (types.SiafundElement, error)
 	FileContractElement(id types.ElementID) (types.FileContractElement, error)
-	UnspentSiacoinElements(address types.Address) ([]types.ElementID, error)
-	UnspentSiafundElements(address types.Address) ([]types.ElementID, error)
+	ElementStatus(id types.ElementID) (ElementStatus, error)
+	UnspentSiacoinElements(address types.Address, cursor Cursor, limit int) ([]types.ElementID, Cursor, error)
+	UnspentSiafundElements(address types.Address, cursor Cursor, limit int) ([]types.ElementID, Cursor, error)
 	Transaction(id types.TransactionID) (types.Transaction, error)
-	Transactions(address types.Address, amount, offset int) ([]types.TransactionID, error)
+	TransactionMetadata(id types.TransactionID) (TransactionMetadata, error)
+	Transactions(address types.Address, cursor Cursor, limit int) ([]types.TransactionID, Cursor, error)
+	ArbitraryDataTransactions(prefix []byte, cursor Cursor, limit int) ([]types.TransactionID, Cursor, error)
+	BalanceHistory(address types.Address, start, end uint64) ([]HistoricalBalance, error)
+	AddressBalance(address types.Address) (sc types.Currency, sf uint64, err error)
+	SiacoinRichList(cursor Cursor, limit int) ([]RankedBalance, Cursor, error)
+	SiafundRichList(cursor Cursor, limit int) ([]RankedBalance, Cursor, error)
+	AddressRank(address types.Address) (siacoinRank, siafundRank uint64, err error)
+	ContractHistory(id types.ElementID) ([]ContractEvent, error)
+	HostContracts(pubkey types.PublicKey, status string, cursor Cursor, limit int) ([]types.ElementID, Cursor, error)
+	RenterContracts(pubkey types.PublicKey, status string, cursor Cursor, limit int) ([]types.ElementID, Cursor, error)
+	HostStats(pubkey types.PublicKey) (HostStats, error)
+	ContractsExpiring(from, to uint64) ([]types.ElementID, error)
+	Attestations(pubkey types.PublicKey, key string, cursor Cursor, limit int) ([]Attestation, Cursor, error)
+	LatestAttestations(pubkey types.PublicKey) ([]Attestation, error)
+	MinerPayout(height uint64) (MinerPayout, error)
+	MinerBlocks(address types.Address, cursor Cursor, limit int) ([]MinerPayout, Cursor, error)
+	MinerBlockCounts(start, end uint64) ([]MinerShare, error)
+	BlockMetrics(start, end uint64) ([]BlockMetrics, error)
+	FeeStats(start, end uint64) ([]FeeStats, error)
+	SiafundClaim(id types.ElementID) (SiafundClaim, error)
+	SiafundClaims(address types.Address, cursor Cursor, limit int) ([]SiafundClaim, Cursor, error)
+	ClaimedSiacoins(address types.Address) (types.Currency, error)
+	SiafundPoolHistory(start, end uint64) ([]SiafundPoolGrowth, error)
+	LatestFoundationChange(height uint64) (FoundationChange, error)
+	FoundationChanges(cursor Cursor, limit int) ([]FoundationChange, Cursor, error)
+	FoundationSubsidies(cursor Cursor, limit int) ([]FoundationSubsidy, Cursor, error)
 	State(index types.ChainIndex) (context consensus.State, err error)
 
 	AddSiacoinElement(sce types.SiacoinElement)
 	AddSiafundElement(sfe types.SiafundElement)
 	AddFileContractElement(fce types.FileContractElement)
 	RemoveElement(id types.ElementID)
+	SpendElement(id types.ElementID, txid types.TransactionID, height uint64)
+	UnspendElement(id types.ElementID)
 	AddChainStats(index types.ChainIndex, stats ChainStats)
-	AddUnspentSiacoinElement(address types.Address, id types.ElementID)
-	AddUnspentSiafundElement(address types.Address, id types.ElementID)
+	RemoveChainStats(index types.ChainIndex)
+	AddBlock(b types.Block)
+	RemoveBlock(id types.BlockID)
+	AddUnspentSiacoinElement(address types.Address, id types.ElementID, leafIndex uint64)
+	AddUnspentSiafundElement(address types.Address, id types.ElementID, leafIndex uint64)
 	RemoveUnspentSiacoinElement(address types.Address, id types.ElementID)
 	RemoveUnspentSiafundElement(address types.Address, id types.ElementID)
-	AddTransaction(txn types.Transaction, addresses []types.Address, block types.ChainIndex)
+	AddTransaction(txn types.Transaction, addresses []types.Address, meta TransactionMetadata)
+	RemoveTransaction(id types.TransactionID)
+	AddBalanceDelta(address types.Address, delta BalanceDelta, sc types.Currency, sf uint64)
+	RemoveBalanceDeltas(height uint64)
+	UpdateAddressBalance(address types.Address, sc types.Currency, sf uint64)
+	AddContractEvent(ev ContractEvent)
+	RemoveContractEvents(height uint64)
+	AddContract(fce types.FileContractElement)
+	UpdateContractStatus(id types.ElementID, status string)
+	UpdateContractWindow(id types.ElementID, windowStart, windowEnd uint64)
+	RemoveContract(id types.ElementID)
+	UpdateHostStats(pubkey types.PublicKey, hs HostStats)
+	AddAttestation(a Attestation, position int)
+	RemoveAttestations(height uint64)
+	AddMinerPayout(mp MinerPayout)
+	RemoveMinerPayout(height uint64)
+	AddBlockMetrics(bm BlockMetrics)
+	RemoveBlockMetrics(height uint64)
+	AddFeeStats(fs FeeStats)
+	RemoveFeeStats(height uint64)
+	AddSiafundClaim(sc SiafundClaim, position int)
+	RemoveSiafundClaims(height uint64)
+	UpdateClaimedSiacoins(address types.Address, claimed types.Currency)
+	AddSiafundPoolGrowth(pg SiafundPoolGrowth)
+	RemoveSiafundPoolGrowth(height uint64)
+	AddFoundationChange(fc FoundationChange, position int)
+	RemoveFoundationChanges(height uint64)
+	AddFoundationSubsidy(fs FoundationSubsidy)
+	RemoveFoundationSubsidy(height uint64)
 	AddState(index types.ChainIndex, context consensus.State)
+	RemoveState(index types.ChainIndex)
 
 	Size() (uint64, error)
 	Commit() error
@@ -46,13 +123,86 @@ type HashStore interface {
 }
 
 // An Explorer contains a database storing information about blocks, outputs,
-// contracts.
+// contracts. Every access to the store and hash store is made with mu held,
+// since reads share the store's pending write transaction.
 type Explorer struct {
 	db       Store
 	mu       sync.Mutex
 	tipStats ChainStats
 	cs       consensus.State
 	hs       HashStore
+
+	subscribers map[EventSubscriber]struct{}
+}
+
+// transactionAddresses returns the unique addresses involved in txn, in the
+// order in which they appear.
+func transactionAddresses(txn types.Transaction) []types.Address {
+	var addrs []types.Address
+	seen := make(map[types.Address]bool)
+	add := func(addr types.Address) {
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	for _, elem := range txn.SiacoinInputs {
+		add(elem.Parent.Address)
+	}
+	for _, elem := range txn.SiacoinOutputs {
+		add(elem.Address)
+	}
+	for _, elem := range txn.SiafundInputs {
+		add(elem.Parent.Address)
+	}
+	for _, elem := range txn.SiafundOutputs {
+		add(elem.Address)
+	}
+	return addrs
+}
+
+// balanceDeltas returns the change in the balance of each address affected by
+// a block, given the elements it spent and created.
+func balanceDeltas(height uint64, b types.Block, spentSC []types.SiacoinElement, spentSF []types.SiafundElement, newSC []types.SiacoinElement, newSF []types.SiafundElement) map[types.Address]BalanceDelta {
+	// elements created and spent within the block don't affect any balance
+	ephemeral := make(map[types.ElementID]bool)
+	for _, txn := range b.Transactions {
+		for _, in := range txn.SiacoinInputs {
+			if in.Parent.LeafIndex == types.EphemeralLeafIndex {
+				ephemeral[in.Parent.ID] = true
+			}
+		}
+	}
+
+	deltas := make(map[types.Address]BalanceDelta)
+	for _, elem := range spentSC {
+		d := deltas[elem.Address]
+		d.SiacoinsOut = d.SiacoinsOut.Add(elem.Value)
+		deltas[elem.Address] = d
+	}
+	for _, elem := range spentSF {
+		d := deltas[elem.Address]
+		d.SiafundsOut += elem.Value
+		deltas[elem.Address] = d
+	}
+	for _, elem := range newSC {
+		if ephemeral[elem.ID] {
+			continue
+		}
+		d := deltas[elem.Address]
+		d.SiacoinsIn = d.SiacoinsIn.Add(elem.Value)
+		deltas[elem.Address] = d
+	}
+	for _, elem := range newSF {
+		d := deltas[elem.Address]
+		d.SiafundsIn += elem.Value
+		deltas[elem.Address] = d
+	}
+	for addr, d := range deltas {
+		d.Height = height
+		deltas[addr] = d
+	}
+	return deltas
 }
 
 // ProcessChainApplyUpdate implements chain.Subscriber.
@@ -61,53 +211,82 @@ func (e *Explorer) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, mayCommit boo
 	defer e.mu.Unlock()
 
 	e.db.AddState(cau.Block.Header.Index(), cau.State)
+	e.db.AddBlock(cau.Block)
 
 	stats := ChainStats{
-		Block:               cau.Block,
-		ActiveContractCost:  e.tipStats.ActiveContractCost,
-		ActiveContractCount: e.tipStats.ActiveContractCount,
-		ActiveContractSize:  e.tipStats.ActiveContractSize,
-		TotalContractCost:   e.tipStats.TotalContractCost,
-		TotalContractSize:   e.tipStats.TotalContractSize,
-		TotalRevisionVolume: e.tipStats.TotalRevisionVolume,
+		Block:                   cau.Block,
+		ActiveContractCost:      e.tipStats.ActiveContractCost,
+		ActiveContractCount:     e.tipStats.ActiveContractCount,
+		ActiveContractSize:      e.tipStats.ActiveContractSize,
+		TotalContractCost:       e.tipStats.TotalContractCost,
+		TotalContractSize:       e.tipStats.TotalContractSize,
+		TotalRevisionVolume:     e.tipStats.TotalRevisionVolume,
+		CirculatingSupply:       e.tipStats.CirculatingSupply,
+		BurnedSiacoins:          e.tipStats.BurnedSiacoins,
+		UnclaimedSiafundRevenue: e.tipStats.UnclaimedSiafundRevenue,
+		SiacoinHolders:          e.tipStats.SiacoinHolders,
+		SiafundHolders:          e.tipStats.SiafundHolders,
 	}
 
-	for _, txn := range cau.Block.Transactions {
-		// get a unique list of all addresses involved in transaction
-		addrMap := make(map[types.Address]struct{})
-		for _, elem := range txn.SiacoinInputs {
-			addrMap[elem.Parent.Address] = struct{}{}
-		}
-		for _, elem := range txn.SiacoinOutputs {
-			addrMap[elem.Address] = struct{}{}
-		}
-		for _, elem := range txn.SiafundInputs {
-			addrMap[elem.Parent.Address] = struct{}{}
-		}
-		for _, elem := range txn.SiafundOutputs {
-			addrMap[elem.Address] = struct{}{}
-		}
-		addrs := make([]types.Address, 0, len(addrMap))
-		for addr := range addrMap {
-			addrs = append(addrs, addr)
+	// map each element consumed in this block to the transaction that spent it
+	spentBy := make(map[types.ElementID]types.TransactionID)
+	for i, txn := range cau.Block.Transactions {
+		txid := txn.ID()
+		for _, id := range transactionInputs(txn) {
+			spentBy[id] = txid
 		}
-		e.db.AddTransaction(txn, addrs, cau.Block.Header.Index())
+		e.db.AddTransaction(txn, transactionAddresses(txn), TransactionMetadata{
+			Block:     cau.Block.Index(),
+			Index:     i,
+			Timestamp: cau.Block.Header.Timestamp,
+		})
+	}
+	for i, a := range blockAttestations(cau.Block) {
+		e.db.AddAttestation(a, i)
+	}
+	e.db.AddMinerPayout(minerPayout(cau.Block, e.cs.BlockReward(), cau.NewSiacoinElements))
+	e.db.AddBlockMetrics(BlockMetrics{
+		Index:      cau.State.Index,
+		Timestamp:  cau.Block.Header.Timestamp,
+		Difficulty: cau.State.Difficulty,
+		TotalWork:  cau.State.TotalWork,
+	})
+	e.db.AddFeeStats(blockFeeStats(cau.Block))
+	parent := e.cs
+	if cau.Block.Header.Height == 0 {
+		// the genesis block is applied to an empty state
+		parent = consensus.State{}
+	}
+	e.db.AddSiafundPoolGrowth(SiafundPoolGrowth{
+		Index:  cau.State.Index,
+		Pool:   cau.State.SiafundPool,
+		Growth: cau.State.SiafundPool.Sub(parent.SiafundPool),
+	})
+	// contract tax leaves circulation when a contract is formed, and returns
+	// to it when siafund holders claim it
+	stats.UnclaimedSiafundRevenue = stats.UnclaimedSiafundRevenue.Add(cau.State.SiafundPool.Sub(parent.SiafundPool))
+	for i, fc := range foundationChanges(parent, cau.Block) {
+		e.db.AddFoundationChange(fc, i)
+	}
+	if fs, ok := foundationSubsidy(parent, cau.Block); ok {
+		e.db.AddFoundationSubsidy(fs)
 	}
 
+	height := cau.State.Index.Height
 	for _, elem := range cau.SpentSiacoins {
-		e.db.RemoveElement(elem.ID)
+		e.db.SpendElement(elem.ID, spentBy[elem.ID], height)
 		e.db.RemoveUnspentSiacoinElement(elem.Address, elem.ID)
 		stats.SpentSiacoinsCount++
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cau.SpentSiafunds {
-		e.db.RemoveElement(elem.ID)
+		e.db.SpendElement(elem.ID, spentBy[elem.ID], height)
 		e.db.RemoveUnspentSiafundElement(elem.Address, elem.ID)
 		stats.SpentSiafundsCount++
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cau.ResolvedFileContracts {
-		e.db.RemoveElement(elem.ID)
+		e.db.SpendElement(elem.ID, spentBy[elem.ID], height)
 		stats.ActiveContractCount--
 		payout := elem.FileContract.RenterOutput.Value.Add(elem.FileContract.HostOutput.Value)
 		stats.ActiveContractCost = stats.ActiveContractCost.Sub(payout)
@@ -117,22 +296,29 @@ func (e *Explorer) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, mayCommit boo
 
 	for _, elem := range cau.NewSiacoinElements {
 		e.db.AddSiacoinElement(elem)
-		e.db.AddUnspentSiacoinElement(elem.Address, elem.ID)
+		if txid, ok := spentBy[elem.ID]; ok {
+			// element was created and spent within this block
+			e.db.SpendElement(elem.ID, txid, height)
+		} else {
+			e.db.AddUnspentSiacoinElement(elem.Address, elem.ID, elem.LeafIndex)
+		}
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cau.NewSiafundElements {
 		e.db.AddSiafundElement(elem)
-		e.db.AddUnspentSiafundElement(elem.Address, elem.ID)
+		e.db.AddUnspentSiafundElement(elem.Address, elem.ID, elem.LeafIndex)
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cau.RevisedFileContracts {
 		e.db.AddFileContractElement(elem)
+		e.db.UpdateContractWindow(elem.ID, elem.FileContract.WindowStart, elem.FileContract.WindowEnd)
 		stats.TotalContractSize += elem.FileContract.Filesize
 		stats.TotalRevisionVolume += elem.FileContract.Filesize
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cau.NewFileContracts {
 		e.db.AddFileContractElement(elem)
+		e.db.AddContract(elem)
 		payout := elem.FileContract.RenterOutput.Value.Add(elem.FileContract.HostOutput.Value)
 		stats.ActiveContractCount++
 		stats.ActiveContractCost = stats.ActiveContractCost.Add(payout)
@@ -141,17 +327,79 @@ func (e *Explorer) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, mayCommit boo
 		stats.TotalContractSize += elem.FileContract.Filesize
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
+	for _, ev := range contractEvents(cau.Block, cau.NewFileContracts) {
+		e.db.AddContractEvent(ev)
+		if ev.Type == ContractEventResolution {
+			status := ContractStatusResolved
+			if !ev.Valid {
+				status = ContractStatusMissed
+				// the host's forfeited collateral leaves the contract
+				// without being paid to anyone
+				stats.BurnedSiacoins = stats.BurnedSiacoins.Add(ev.Contract.HostOutput.Value.Sub(ev.HostPayout.Value))
+			}
+			e.db.UpdateContractStatus(ev.ContractID, status)
+		}
+	}
+	for pubkey, d := range hostDeltas(cau.Block, cau.NewFileContracts) {
+		hs, err := e.db.HostStats(pubkey)
+		if err != nil {
+			return err
+		}
+		e.db.UpdateHostStats(pubkey, d.apply(hs))
+	}
+	claimed := make(map[types.Address]types.Currency)
+	for i, sc := range siafundClaims(e.cs, cau.Block) {
+		e.db.AddSiafundClaim(sc, i)
+		claimed[sc.Address] = claimed[sc.Address].Add(sc.Claim)
+		stats.UnclaimedSiafundRevenue = stats.UnclaimedSiafundRevenue.Sub(sc.Claim)
+	}
+	for addr, c := range claimed {
+		total, err := e.db.ClaimedSiacoins(addr)
+		if err != nil {
+			return err
+		}
+		e.db.UpdateClaimedSiacoins(addr, total.Add(c))
+	}
 
+	deltas := balanceDeltas(height, cau.Block, cau.SpentSiacoins, cau.SpentSiafunds, cau.NewSiacoinElements, cau.NewSiafundElements)
+	for addr, d := range deltas {
+		prevSC, prevSF, err := e.db.AddressBalance(addr)
+		if err != nil {
+			return err
+		}
+		sc, sf := prevSC.Add(d.SiacoinsIn).Sub(d.SiacoinsOut), prevSF+d.SiafundsIn-d.SiafundsOut
+		if prevSC.IsZero() && !sc.IsZero() {
+			stats.SiacoinHolders++
+		} else if !prevSC.IsZero() && sc.IsZero() {
+			stats.SiacoinHolders--
+		}
+		if prevSF == 0 && sf != 0 {
+			stats.SiafundHolders++
+		} else if prevSF != 0 && sf == 0 {
+			stats.SiafundHolders--
+		}
+		e.db.AddBalanceDelta(addr, d, sc, sf)
+		e.db.UpdateAddressBalance(addr, sc, sf)
+		if addr == types.VoidAddress {
+			stats.BurnedSiacoins = stats.BurnedSiacoins.Add(d.SiacoinsIn).Sub(d.SiacoinsOut)
+		} else {
+			stats.CirculatingSupply = stats.CirculatingSupply.Add(d.SiacoinsIn).Sub(d.SiacoinsOut)
+		}
+	}
+	stats.SiacoinSupply = stats.CirculatingSupply.Add(stats.BurnedSiacoins).Add(stats.ActiveContractCost).Add(stats.UnclaimedSiafundRevenue)
+	stats.SiafundPool = cau.State.SiafundPool
 	e.db.AddChainStats(cau.State.Index, stats)
 
 	e.cs, e.tipStats = cau.State, stats
 	if mayCommit {
 		if err := e.hs.Commit(); err != nil {
 			return err
+		} else if err := e.db.Commit(); err != nil {
+			return err
 		}
-		return e.db.Commit()
 	}
 
+	e.publish(blockEvents(cau.Block, cau.NewSiacoinElements, false))
 	return nil
 }
 
@@ -161,17 +409,17 @@ func (e *Explorer) ProcessChainRevertUpdate(cru *chain.RevertUpdate) error {
 	defer e.mu.Unlock()
 
 	for _, elem := range cru.SpentSiacoins {
-		e.db.AddSiacoinElement(elem)
-		e.db.AddUnspentSiacoinElement(elem.Address, elem.ID)
+		e.db.UnspendElement(elem.ID)
+		e.db.AddUnspentSiacoinElement(elem.Address, elem.ID, elem.LeafIndex)
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cru.SpentSiafunds {
-		e.db.AddSiafundElement(elem)
-		e.db.AddUnspentSiafundElement(elem.Address, elem.ID)
+		e.db.UnspendElement(elem.ID)
+		e.db.AddUnspentSiafundElement(elem.Address, elem.ID, elem.LeafIndex)
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 	for _, elem := range cru.ResolvedFileContracts {
-		e.db.AddFileContractElement(elem)
+		e.db.UnspendElement(elem.ID)
 		e.hs.ModifyLeaf(elem.StateElement)
 	}
 
@@ -189,28 +437,93 @@ func (e *Explorer) ProcessChainRevertUpdate(cru *chain.RevertUpdate) error {
 	for _, txn := range cru.Block.Transactions {
 		for _, rev := range txn.FileContractRevisions {
 			e.db.AddFileContractElement(rev.Parent)
+			e.db.UpdateContractWindow(rev.Parent.ID, rev.Parent.FileContract.WindowStart, rev.Parent.FileContract.WindowEnd)
 			e.hs.ModifyLeaf(rev.Parent.StateElement)
 		}
+		for _, fcr := range txn.FileContractResolutions {
+			e.db.UpdateContractStatus(fcr.Parent.ID, ContractStatusActive)
+		}
 	}
 	for _, elem := range cru.NewFileContracts {
 		e.db.RemoveElement(elem.ID)
+		e.db.RemoveContract(elem.ID)
+	}
+	for pubkey, d := range hostDeltas(cru.Block, cru.NewFileContracts) {
+		hs, err := e.db.HostStats(pubkey)
+		if err != nil {
+			return err
+		}
+		e.db.UpdateHostStats(pubkey, d.revert(hs))
+	}
+	claimed := make(map[types.Address]types.Currency)
+	for _, sc := range siafundClaims(cru.State, cru.Block) {
+		claimed[sc.Address] = claimed[sc.Address].Add(sc.Claim)
+	}
+	for addr, c := range claimed {
+		total, err := e.db.ClaimedSiacoins(addr)
+		if err != nil {
+			return err
+		}
+		e.db.UpdateClaimedSiacoins(addr, total.Sub(c))
 	}
 
-	oldStats, err := e.ChainStats(cru.State.Index)
+	for _, txn := range cru.Block.Transactions {
+		e.db.RemoveTransaction(txn.ID())
+	}
+	index := cru.Block.Index()
+	deltas := balanceDeltas(index.Height, cru.Block, cru.SpentSiacoins, cru.SpentSiafunds, cru.NewSiacoinElements, cru.NewSiafundElements)
+	for addr, d := range deltas {
+		sc, sf, err := e.db.AddressBalance(addr)
+		if err != nil {
+			return err
+		}
+		e.db.UpdateAddressBalance(addr, sc.Add(d.SiacoinsOut).Sub(d.SiacoinsIn), sf+d.SiafundsOut-d.SiafundsIn)
+	}
+	e.db.RemoveBalanceDeltas(index.Height)
+	e.db.RemoveContractEvents(index.Height)
+	e.db.RemoveAttestations(index.Height)
+	e.db.RemoveMinerPayout(index.Height)
+	e.db.RemoveBlockMetrics(index.Height)
+	e.db.RemoveFeeStats(index.Height)
+	e.db.RemoveSiafundClaims(index.Height)
+	e.db.RemoveSiafundPoolGrowth(index.Height)
+	e.db.RemoveFoundationChanges(index.Height)
+	e.db.RemoveFoundationSubsidy(index.Height)
+	e.db.RemoveChainStats(index)
+	e.db.RemoveState(index)
+	e.db.RemoveBlock(index.ID)
+
+	oldStats, err := e.db.ChainStats(cru.State.Index)
 	if err != nil {
 		return err
 	}
 
 	// update validation context
 	e.cs, e.tipStats = cru.State, oldStats
-	return e.db.Commit()
+	if err := e.db.Commit(); err != nil {
+		return err
+	}
+
+	e.publish(blockEvents(cru.Block, cru.NewSiacoinElements, true))
+	return nil
 }
 
-// NewExplorer creates a new explorer.
-func NewExplorer(cs consensus.State, store Store, hashStore HashStore) *Explorer {
-	return &Explorer{
-		cs: cs,
-		db: store,
-		hs: hashStore,
+// NewExplorer creates a new explorer. cs is the state of the latest block
+// processed by store, if any; its chain stats are carried forward to the
+// blocks processed next.
+func NewExplorer(cs consensus.State, store Store, hashStore HashStore) (*Explorer, error) {
+	stats, err := store.ChainStats(cs.Index)
+	if errors.Is(err, ErrNotFound) {
+		stats = ChainStats{}
+	} else if err != nil {
+		return nil, err
 	}
+	return &Explorer{
+		cs:       cs,
+		db:       store,
+		hs:       hashStore,
+		tipStats: stats,
+
+		subscribers: make(map[EventSubscriber]struct{}),
+	}, nil
 }
@@ -0,0 +1,145 @@
+package explorer
+
+import (
+	"sort"
+
+	"go.sia.tech/core/types"
+)
+
+// FeeStats summarizes the miner fees paid by the transactions in a block. Fee
+// rates are in hastings per byte of encoded transaction.
+type FeeStats struct {
+	Index        types.ChainIndex
+	Transactions uint64
+	TotalFees    types.Currency
+
+	MinFee    types.Currency
+	MedianFee types.Currency
+	MaxFee    types.Currency
+
+	MinFeeRate    types.Currency
+	MedianFeeRate types.Currency
+	MaxFeeRate    types.Currency
+}
+
+// EncodeTo implements types.EncoderTo.
+func (fs FeeStats) EncodeTo(e *types.Encoder) {
+	fs.Index.EncodeTo(e)
+	e.WriteUint64(fs.Transactions)
+	fs.TotalFees.EncodeTo(e)
+	fs.MinFee.EncodeTo(e)
+	fs.MedianFee.EncodeTo(e)
+	fs.MaxFee.EncodeTo(e)
+	fs.MinFeeRate.EncodeTo(e)
+	fs.MedianFeeRate.EncodeTo(e)
+	fs.MaxFeeRate.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (fs *FeeStats) DecodeFrom(d *types.Decoder) {
+	fs.Index.DecodeFrom(d)
+	fs.Transactions = d.ReadUint64()
+	fs.TotalFees.DecodeFrom(d)
+	fs.MinFee.DecodeFrom(d)
+	fs.MedianFee.DecodeFrom(d)
+	fs.MaxFee.DecodeFrom(d)
+	fs.MinFeeRate.DecodeFrom(d)
+	fs.MedianFeeRate.DecodeFrom(d)
+	fs.MaxFeeRate.DecodeFrom(d)
+}
+
+// A FeeEstimate suggests fee rates, in hastings per byte of encoded
+// transaction, for getting a transaction confirmed. Low is the lowest rate
+// recently accepted by miners, Medium is the typical recent rate, and High
+// additionally accounts for the transactions currently competing for space in
+// the transaction pool.
+type FeeEstimate struct {
+	Low    types.Currency `json:"low"`
+	Medium types.Currency `json:"medium"`
+	High   types.Currency `json:"high"`
+
+	PoolTransactions int    `json:"poolTransactions"`
+	PoolSize         uint64 `json:"poolSize"`
+}
+
+// feeRate returns the fee rate of txn in hastings per byte.
+func feeRate(txn types.Transaction) types.Currency {
+	return txn.MinerFee.Div64(uint64(types.EncodedLen(txn)))
+}
+
+// medianCurrency sorts cs and returns its median, or zero if it is empty.
+func medianCurrency(cs []types.Currency) types.Currency {
+	if len(cs) == 0 {
+		return types.ZeroCurrency
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Cmp(cs[j]) < 0 })
+	return cs[len(cs)/2]
+}
+
+// blockFeeStats returns the FeeStats for b.
+func blockFeeStats(b types.Block) FeeStats {
+	fs := FeeStats{
+		Index:        b.Index(),
+		Transactions: uint64(len(b.Transactions)),
+	}
+	if len(b.Transactions) == 0 {
+		return fs
+	}
+	fees := make([]types.Currency, len(b.Transactions))
+	rates := make([]types.Currency, len(b.Transactions))
+	for i, txn := range b.Transactions {
+		fees[i], rates[i] = txn.MinerFee, feeRate(txn)
+		fs.TotalFees = fs.TotalFees.Add(txn.MinerFee)
+	}
+	fs.MedianFee, fs.MedianFeeRate = medianCurrency(fees), medianCurrency(rates)
+	fs.MinFee, fs.MaxFee = fees[0], fees[len(fees)-1]
+	fs.MinFeeRate, fs.MaxFeeRate = rates[0], rates[len(rates)-1]
+	return fs
+}
+
+// EstimateFee returns a fee estimate derived from the fee stats of recent
+// blocks and the transactions currently in the pool. Blocks without
+// transactions are ignored.
+func EstimateFee(recent []FeeStats, pool []types.Transaction) FeeEstimate {
+	var mins, medians []types.Currency
+	for _, fs := range recent {
+		if fs.Transactions > 0 {
+			mins = append(mins, fs.MinFeeRate)
+			medians = append(medians, fs.MedianFeeRate)
+		}
+	}
+	rates := make([]types.Currency, len(pool))
+	var size uint64
+	for i, txn := range pool {
+		rates[i] = feeRate(txn)
+		size += uint64(types.EncodedLen(txn))
+	}
+
+	fe := FeeEstimate{
+		Low:              medianCurrency(mins),
+		Medium:           medianCurrency(medians),
+		PoolTransactions: len(pool),
+		PoolSize:         size,
+	}
+	// to beat the backlog, a transaction must pay more than most of the
+	// transactions already waiting
+	fe.High = fe.Medium
+	if len(rates) > 0 {
+		sort.Slice(rates, func(i, j int) bool { return rates[i].Cmp(rates[j]) < 0 })
+		if backlog := rates[len(rates)*3/4]; backlog.Cmp(fe.High) > 0 {
+			fe.High = backlog
+		}
+	}
+	if fe.Low.Cmp(fe.Medium) > 0 {
+		fe.Low = fe.Medium
+	}
+	return fe
+}
+
+// FeeStats returns the fee stats of each block in the best chain at heights in
+// [start, end].
+func (e *Explorer) FeeStats(start, end uint64) ([]FeeStats, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.FeeStats(start, end)
+}
@@ -0,0 +1,121 @@
+package explorer
+
+import (
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+)
+
+// A FoundationChange records a transaction changing the Foundation address.
+type FoundationChange struct {
+	Index       types.ChainIndex
+	Transaction types.TransactionID
+	Previous    types.Address
+	Address     types.Address
+}
+
+// EncodeTo implements types.EncoderTo.
+func (fc FoundationChange) EncodeTo(e *types.Encoder) {
+	fc.Index.EncodeTo(e)
+	fc.Transaction.EncodeTo(e)
+	fc.Previous.EncodeTo(e)
+	fc.Address.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (fc *FoundationChange) DecodeFrom(d *types.Decoder) {
+	fc.Index.DecodeFrom(d)
+	fc.Transaction.DecodeFrom(d)
+	fc.Previous.DecodeFrom(d)
+	fc.Address.DecodeFrom(d)
+}
+
+// A FoundationSubsidy records a subsidy paid to the Foundation by a block.
+// Element is the ID of the siacoin element created for the subsidy.
+type FoundationSubsidy struct {
+	Index   types.ChainIndex
+	Element types.ElementID
+	Address types.Address
+	Value   types.Currency
+}
+
+// EncodeTo implements types.EncoderTo.
+func (fs FoundationSubsidy) EncodeTo(e *types.Encoder) {
+	fs.Index.EncodeTo(e)
+	fs.Element.EncodeTo(e)
+	fs.Address.EncodeTo(e)
+	fs.Value.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (fs *FoundationSubsidy) DecodeFrom(d *types.Decoder) {
+	fs.Index.DecodeFrom(d)
+	fs.Element.DecodeFrom(d)
+	fs.Address.DecodeFrom(d)
+	fs.Value.DecodeFrom(d)
+}
+
+// foundationChanges returns the Foundation address changes made by b, which
+// was applied to the state s, in the order in which they occurred.
+func foundationChanges(s consensus.State, b types.Block) []FoundationChange {
+	index := b.Index()
+	current := s.FoundationAddress
+	var changes []FoundationChange
+	for _, txn := range b.Transactions {
+		if txn.NewFoundationAddress == types.VoidAddress {
+			continue
+		}
+		changes = append(changes, FoundationChange{
+			Index:       index,
+			Transaction: txn.ID(),
+			Previous:    current,
+			Address:     txn.NewFoundationAddress,
+		})
+		current = txn.NewFoundationAddress
+	}
+	return changes
+}
+
+// foundationSubsidy returns the Foundation subsidy paid by b, which was
+// applied to the state s, mirroring the output created by consensus. It
+// returns false if b pays no subsidy.
+func foundationSubsidy(s consensus.State, b types.Block) (FoundationSubsidy, bool) {
+	subsidy := s.FoundationSubsidy()
+	if subsidy.IsZero() {
+		return FoundationSubsidy{}, false
+	}
+	return FoundationSubsidy{
+		Index:   b.Index(),
+		Element: b.FoundationOutputID(),
+		Address: s.FoundationAddress,
+		Value:   subsidy,
+	}, true
+}
+
+// FoundationAddress returns the Foundation address as of the block at the
+// specified height.
+func (e *Explorer) FoundationAddress(height uint64) (types.Address, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	fc, err := e.db.LatestFoundationChange(height)
+	if err == ErrNotFound {
+		// the address has never been set
+		return types.VoidAddress, nil
+	}
+	return fc.Address, err
+}
+
+// FoundationChanges returns up to limit changes to the Foundation address,
+// newest first, starting at cursor.
+func (e *Explorer) FoundationChanges(cursor Cursor, limit int) ([]FoundationChange, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.FoundationChanges(cursor, limit)
+}
+
+// FoundationSubsidies returns up to limit subsidies paid to the Foundation,
+// newest first, starting at cursor.
+func (e *Explorer) FoundationSubsidies(cursor Cursor, limit int) ([]FoundationSubsidy, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.FoundationSubsidies(cursor, limit)
+}
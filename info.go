@@ -1,14 +1,15 @@
 package explorer
 
 import (
-	"errors"
+	"time"
 
 	"go.sia.tech/core/consensus"
 	"go.sia.tech/core/types"
 )
 
 // ChainStats contains a bunch of statistics about the consensus set as they
-// were at a specific block.
+// were at a specific block. Block is not included in the encoding of
+// ChainStats; stores persist it alongside the block index instead.
 type ChainStats struct {
 	Block types.Block
 
@@ -23,15 +24,28 @@ type ChainStats struct {
 	TotalContractCost   types.Currency
 	TotalContractSize   uint64
 	TotalRevisionVolume uint64
+
+	// Monetary supply. SiacoinSupply is the total value of all unspent
+	// siacoin outputs, active contracts, unclaimed contract tax, and
+	// collateral lost by missed storage proofs; it is the sum of
+	// CirculatingSupply, BurnedSiacoins (outputs sent to the void address
+	// and lost collateral), ActiveContractCost, and
+	// UnclaimedSiafundRevenue. SiafundPool is the total contract tax
+	// collected, as tracked by consensus; the part of it not yet claimed by
+	// siafund holders is UnclaimedSiafundRevenue.
+	SiacoinSupply           types.Currency
+	CirculatingSupply       types.Currency
+	BurnedSiacoins          types.Currency
+	SiafundPool             types.Currency
+	UnclaimedSiafundRevenue types.Currency
+
+	// Number of addresses holding siacoins and siafunds.
+	SiacoinHolders uint64
+	SiafundHolders uint64
 }
 
 // EncodeTo implements types.EncoderTo.
 func (cs ChainStats) EncodeTo(e *types.Encoder) {
-	cs.Block.Header.EncodeTo(e)
-	e.WritePrefix(len(cs.Block.Transactions))
-	for _, txn := range cs.Block.Transactions {
-		txn.EncodeTo(e)
-	}
 	e.WriteUint64(cs.SpentSiacoinsCount)
 	e.WriteUint64(cs.SpentSiafundsCount)
 	cs.ActiveContractCost.EncodeTo(e)
@@ -40,15 +54,17 @@ func (cs ChainStats) EncodeTo(e *types.Encoder) {
 	cs.TotalContractCost.EncodeTo(e)
 	e.WriteUint64(cs.TotalContractSize)
 	e.WriteUint64(cs.TotalRevisionVolume)
+	cs.SiacoinSupply.EncodeTo(e)
+	cs.CirculatingSupply.EncodeTo(e)
+	cs.BurnedSiacoins.EncodeTo(e)
+	cs.SiafundPool.EncodeTo(e)
+	cs.UnclaimedSiafundRevenue.EncodeTo(e)
+	e.WriteUint64(cs.SiacoinHolders)
+	e.WriteUint64(cs.SiafundHolders)
 }
 
 // DecodeFrom implements types.DecoderFrom.
 func (cs *ChainStats) DecodeFrom(d *types.Decoder) {
-	cs.Block.Header.DecodeFrom(d)
-	cs.Block.Transactions = make([]types.Transaction, d.ReadPrefix())
-	for i := range cs.Block.Transactions {
-		cs.Block.Transactions[i].DecodeFrom(d)
-	}
 	cs.SpentSiacoinsCount = d.ReadUint64()
 	cs.SpentSiafundsCount = d.ReadUint64()
 	cs.ActiveContractCost.DecodeFrom(d)
@@ -57,118 +73,271 @@ func (cs *ChainStats) DecodeFrom(d *types.Decoder) {
 	cs.TotalContractCost.DecodeFrom(d)
 	cs.TotalContractSize = d.ReadUint64()
 	cs.TotalRevisionVolume = d.ReadUint64()
+	cs.SiacoinSupply.DecodeFrom(d)
+	cs.CirculatingSupply.DecodeFrom(d)
+	cs.BurnedSiacoins.DecodeFrom(d)
+	cs.SiafundPool.DecodeFrom(d)
+	cs.UnclaimedSiafundRevenue.DecodeFrom(d)
+	cs.SiacoinHolders = d.ReadUint64()
+	cs.SiafundHolders = d.ReadUint64()
+}
+
+// TransactionMetadata contains information about the block a transaction was
+// confirmed in.
+type TransactionMetadata struct {
+	Block     types.ChainIndex
+	Index     int // position of the transaction within the block
+	Timestamp time.Time
+}
+
+// An ElementStatus describes whether an element has been spent and, if so, by
+// which transaction. File contracts are considered spent once resolved.
+type ElementStatus struct {
+	Spent       bool
+	SpentBy     types.TransactionID
+	SpentHeight uint64
+}
+
+// A BalanceDelta records the value an address received and spent in a
+// particular block.
+type BalanceDelta struct {
+	Height      uint64
+	SiacoinsIn  types.Currency
+	SiacoinsOut types.Currency
+	SiafundsIn  uint64
+	SiafundsOut uint64
+}
+
+// A HistoricalBalance is the balance of an address as of a particular height.
+type HistoricalBalance struct {
+	Height   uint64
+	Siacoins types.Currency
+	Siafunds uint64
+}
+
+// A Cursor is an opaque token marking a position in a paginated list. The
+// empty Cursor marks the start of the list. Lists are ordered newest-first.
+type Cursor string
+
+// Tip returns the index of the latest block processed by the explorer.
+func (e *Explorer) Tip() types.ChainIndex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cs.Index
 }
 
 // ChainStatsLatest returns stats about the latest black.
 func (e *Explorer) ChainStatsLatest() (ChainStats, error) {
-	return e.ChainStats(e.cs.Index)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.ChainStats(e.cs.Index)
 }
 
 // ChainStats returns stats about the black at the the specified height.
 func (e *Explorer) ChainStats(index types.ChainIndex) (ChainStats, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.ChainStats(index)
 }
 
-// SiacoinBalance returns the siacoin balance of an address.
-func (e *Explorer) SiacoinBalance(address types.Address) (types.Currency, error) {
+// SupplyStats contains the monetary supply statistics of a block. See
+// ChainStats for the definition of each field.
+type SupplyStats struct {
+	Index                   types.ChainIndex
+	Timestamp               time.Time
+	SiacoinSupply           types.Currency
+	CirculatingSupply       types.Currency
+	LockedSiacoins          types.Currency
+	BurnedSiacoins          types.Currency
+	SiafundPool             types.Currency
+	UnclaimedSiafundRevenue types.Currency
+}
+
+// SupplyStats returns the supply statistics of every step'th block in the best
+// chain at heights in [start, end].
+func (e *Explorer) SupplyStats(start, end, step uint64) ([]SupplyStats, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-
-	ids, err := e.db.UnspentSiacoinElements(address)
-	if err != nil {
-		return types.Currency{}, err
+	if step == 0 {
+		step = 1
 	}
-	var sum types.Currency
-	for _, id := range ids {
-		elem, err := e.db.SiacoinElement(id)
+	var series []SupplyStats
+	for height := start; height <= end; height += step {
+		b, err := e.db.BlockByHeight(height)
 		if err != nil {
-			return types.Currency{}, err
+			return nil, err
 		}
-		sum = sum.Add(elem.Value)
+		cs, err := e.db.ChainStats(b.Index())
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, SupplyStats{
+			Index:                   b.Index(),
+			Timestamp:               b.Header.Timestamp,
+			SiacoinSupply:           cs.SiacoinSupply,
+			CirculatingSupply:       cs.CirculatingSupply,
+			LockedSiacoins:          cs.ActiveContractCost,
+			BurnedSiacoins:          cs.BurnedSiacoins,
+			SiafundPool:             cs.SiafundPool,
+			UnclaimedSiafundRevenue: cs.UnclaimedSiafundRevenue,
+		})
 	}
-	return sum, nil
+	return series, nil
+}
+
+// BlockByHeight returns the block at the specified height in the best chain.
+func (e *Explorer) BlockByHeight(height uint64) (types.Block, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.BlockByHeight(height)
+}
+
+// BlockByID returns the block with the specified ID.
+func (e *Explorer) BlockByID(id types.BlockID) (types.Block, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.BlockByID(id)
+}
+
+// AddressBalance returns the siacoin and siafund balance of an address.
+func (e *Explorer) AddressBalance(address types.Address) (sc types.Currency, sf uint64, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.AddressBalance(address)
+}
+
+// SiacoinBalance returns the siacoin balance of an address.
+func (e *Explorer) SiacoinBalance(address types.Address) (types.Currency, error) {
+	sc, _, err := e.AddressBalance(address)
+	return sc, err
 }
 
 // SiafundBalance returns the siafund balance of an address.
 func (e *Explorer) SiafundBalance(address types.Address) (uint64, error) {
+	_, sf, err := e.AddressBalance(address)
+	return sf, err
+}
+
+// BalanceHistory returns the balance of an address at each height in the range
+// [start, end] at which a block affected it.
+func (e *Explorer) BalanceHistory(address types.Address, start, end uint64) ([]HistoricalBalance, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	return e.db.BalanceHistory(address, start, end)
+}
 
-	ids, err := e.db.UnspentSiafundElements(address)
-	if err != nil {
-		return 0, err
-	}
-	var sum uint64
-	for _, id := range ids {
-		elem, err := e.db.SiafundElement(id)
-		if err != nil {
-			return 0, err
-		}
-		sum += elem.Value
-	}
-	return sum, nil
+// UnspentSiacoinElements returns up to limit unspent siacoin elements
+// associated with the specified address, newest first, starting at cursor. The
+// returned Cursor marks the next page, and is empty on the last page.
+func (e *Explorer) UnspentSiacoinElements(address types.Address, cursor Cursor, limit int) ([]types.ElementID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.UnspentSiacoinElements(address, cursor, limit)
 }
 
-// UnspentSiacoinElements returns unspent siacoin elements associated with the
-// specified address.
-func (e *Explorer) UnspentSiacoinElements(address types.Address) ([]types.ElementID, error) {
-	return e.db.UnspentSiacoinElements(address)
+// UnspentSiafundElements returns up to limit unspent siafund elements
+// associated with the specified address, newest first, starting at cursor. The
+// returned Cursor marks the next page, and is empty on the last page.
+func (e *Explorer) UnspentSiafundElements(address types.Address, cursor Cursor, limit int) ([]types.ElementID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.UnspentSiafundElements(address, cursor, limit)
 }
 
-// UnspentSiafundElements returns unspent siafund elements associated with the
-// specified address.
-func (e *Explorer) UnspentSiafundElements(address types.Address) ([]types.ElementID, error) {
-	return e.db.UnspentSiafundElements(address)
+// Transactions returns up to limit IDs of transactions associated with the
+// specified address, newest first, starting at cursor. The returned Cursor
+// marks the next page, and is empty on the last page.
+func (e *Explorer) Transactions(address types.Address, cursor Cursor, limit int) ([]types.TransactionID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.Transactions(address, cursor, limit)
 }
 
-// Transactions returns the latest n transaction IDs associated with the
-// specified address.
-func (e *Explorer) Transactions(address types.Address, amount, offset int) ([]types.TransactionID, error) {
-	return e.db.Transactions(address, amount, offset)
+// ArbitraryDataPrefixSize is the number of leading bytes of each transaction's
+// arbitrary data that are indexed, and thus the maximum length of a prefix
+// passed to ArbitraryDataTransactions.
+const ArbitraryDataPrefixSize = 64
+
+// ArbitraryDataTransactions returns up to limit IDs of transactions whose
+// arbitrary data begins with prefix, newest first, starting at cursor. prefix
+// must not be longer than ArbitraryDataPrefixSize.
+func (e *Explorer) ArbitraryDataTransactions(prefix []byte, cursor Cursor, limit int) ([]types.TransactionID, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.ArbitraryDataTransactions(prefix, cursor, limit)
 }
 
 // SiacoinElement returns the siacoin element associated with the specified ID.
 func (e *Explorer) SiacoinElement(id types.ElementID) (types.SiacoinElement, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.SiacoinElement(id)
 }
 
 // SiafundElement returns the siafund element associated with the specified ID.
 func (e *Explorer) SiafundElement(id types.ElementID) (types.SiafundElement, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.SiafundElement(id)
 }
 
 // FileContractElement returns the file contract element associated with the specified ID.
 func (e *Explorer) FileContractElement(id types.ElementID) (types.FileContractElement, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.FileContractElement(id)
 }
 
+// ElementStatus returns the spend status of the element with the given ID.
+func (e *Explorer) ElementStatus(id types.ElementID) (ElementStatus, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.ElementStatus(id)
+}
+
 // Transaction returns the transaction with the given ID.
 func (e *Explorer) Transaction(id types.TransactionID) (types.Transaction, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.Transaction(id)
 }
 
+// TransactionMetadata returns information about the block the transaction with
+// the given ID was confirmed in.
+func (e *Explorer) TransactionMetadata(id types.TransactionID) (TransactionMetadata, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.TransactionMetadata(id)
+}
+
 // State returns the chain state for a given chain index.
 func (e *Explorer) State(index types.ChainIndex) (consensus.State, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.db.State(index)
 }
 
 // MerkleProof returns the current merkle proof for a given element.
 func (e *Explorer) MerkleProof(id types.ElementID) ([]types.Hash256, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	var index uint64
-	if elem, err := e.SiacoinElement(id); err == nil {
+	if elem, err := e.db.SiacoinElement(id); err == nil {
 		index = elem.LeafIndex
-	} else if elem, err := e.SiafundElement(id); err == nil {
+	} else if elem, err := e.db.SiafundElement(id); err == nil {
 		index = elem.LeafIndex
-	} else if elem, err := e.FileContractElement(id); err == nil {
+	} else if elem, err := e.db.FileContractElement(id); err == nil {
 		index = elem.LeafIndex
 	} else {
-		return nil, errors.New("no such element")
+		return nil, ErrNotFound
 	}
 	return e.hs.MerkleProof(index)
 }
 
 // Size returns the combined size in bytes of the SQL store and the hash store.
 func (e *Explorer) Size() (uint64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	dbSize, err := e.db.Size()
 	if err != nil {
 		return 0, err
@@ -2,12 +2,15 @@ package explorer_test
 
 import (
 	"encoding/binary"
-	"math"
+	"errors"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"go.sia.tech/core/chain"
 	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/merkle"
 	"go.sia.tech/core/types"
 	"go.sia.tech/explorer"
 	"go.sia.tech/explorer/internal/chainutil"
@@ -29,6 +32,46 @@ func addGenesisElements(e *explorer.Explorer, block types.Block) error {
 	}, true)
 }
 
+// The simulator's genesis block creates 550 SC of siacoin outputs and ten
+// contracts worth 825 SC, which pay 33 SC (4%) of tax into the siafund pool,
+// and mines 299,999 SC to the void address, for a supply of 301,407 SC. Its
+// 550 siafunds were created with an empty pool, so they can claim 0.0001 of
+// the pool's value per siafund.
+var (
+	genesisCirculating = types.Siacoins(550)
+	genesisLocked      = types.Siacoins(825)
+	genesisTax         = types.Siacoins(33)
+	genesisBurned      = types.Siacoins(299999)
+	genesisSupply      = types.Siacoins(301407)
+)
+
+// blockReward returns the reward for the block at the given height, which the
+// simulator mines to the void address: 300,000 SC, less 1 SC per block.
+func blockReward(height uint64) types.Currency {
+	return types.Siacoins(uint32(300000 - height))
+}
+
+// newTestExplorer returns a chain simulator, a chain manager for it, and an
+// Explorer subscribed to the manager that has processed the genesis block.
+func newTestExplorer(tb testing.TB) (*chainutil.ChainSim, *chain.Manager, *explorer.Explorer) {
+	sim := chainutil.NewChainSim()
+	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.State)
+
+	hs, err := explorerutil.NewHashStore(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cm.AddSubscriber(e, cm.Tip())
+	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
+		tb.Fatal(err)
+	}
+	return sim, cm, e
+}
+
 func TestSiacoinElements(t *testing.T) {
 	sim := chainutil.NewChainSim()
 	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.State)
@@ -38,7 +81,10 @@ func TestSiacoinElements(t *testing.T) {
 		t.Fatal(err)
 	}
 	explorerStore := explorerutil.NewEphemeralStore()
-	e := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	if err != nil {
+		t.Fatal(err)
+	}
 	cm.AddSubscriber(e, cm.Tip())
 	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
 		t.Fatal(err)
@@ -85,7 +131,7 @@ func TestSiacoinElements(t *testing.T) {
 			t.Fatal("balances don't equal")
 		}
 
-		outputs, err := e.UnspentSiacoinElements(changeAddr)
+		outputs, _, err := e.UnspentSiacoinElements(changeAddr, "", 100)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -112,7 +158,7 @@ func TestSiacoinElements(t *testing.T) {
 			t.Fatal("accumulator should not see output as spent")
 		}
 
-		txns, err := e.Transactions(changeAddr, math.MaxInt64, 0)
+		txns, _, err := e.Transactions(changeAddr, "", 100)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -129,6 +175,20 @@ func TestSiacoinElements(t *testing.T) {
 		if txn.ID() != txns0.ID() {
 			t.Fatal("wrong transaction")
 		}
+
+		meta, err := e.TransactionMetadata(txns[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tip := cm.TipState().Index; meta.Block != tip {
+			t.Fatal("wrong confirmation block", meta.Block, tip)
+		} else if meta.Index != 0 {
+			t.Fatal("wrong position in block", meta.Index)
+		} else if block, err := e.BlockByID(meta.Block.ID); err != nil {
+			t.Fatal(err)
+		} else if !meta.Timestamp.Equal(block.Header.Timestamp) {
+			t.Fatal("wrong timestamp", meta.Timestamp, block.Header.Timestamp)
+		}
 	}
 }
 
@@ -141,7 +201,10 @@ func TestChainStatsSiacoins(t *testing.T) {
 		t.Fatal(err)
 	}
 	explorerStore := explorerutil.NewEphemeralStore()
-	e := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	if err != nil {
+		t.Fatal(err)
+	}
 	cm.AddSubscriber(e, cm.Tip())
 	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
 		t.Fatal(err)
@@ -167,7 +230,9 @@ func TestChainStatsSiacoins(t *testing.T) {
 	}
 	expected := explorer.ChainStats{
 		// don't compare these
-		Block: stats.Block,
+		Block:          stats.Block,
+		SiacoinHolders: stats.SiacoinHolders,
+		SiafundHolders: stats.SiafundHolders,
 
 		SpentSiacoinsCount:  0,
 		SpentSiafundsCount:  0,
@@ -177,6 +242,14 @@ func TestChainStatsSiacoins(t *testing.T) {
 		TotalContractCost:   types.Siacoins(825),
 		TotalContractSize:   0,
 		TotalRevisionVolume: 0,
+
+		// funding the wallet moved coins within circulation; the rewards
+		// of both blocks were burned
+		SiacoinSupply:           genesisSupply.Add(blockReward(1)).Add(blockReward(2)),
+		CirculatingSupply:       genesisCirculating,
+		BurnedSiacoins:          genesisBurned.Add(blockReward(1)).Add(blockReward(2)),
+		SiafundPool:             genesisTax,
+		UnclaimedSiafundRevenue: genesisTax,
 	}
 	if !reflect.DeepEqual(stats, expected) {
 		t.Fatal("chainstats don't match")
@@ -188,6 +261,7 @@ func TestChainStatsSiacoins(t *testing.T) {
 	}
 	t.Logf("Begin size: %d bytes", size)
 
+	supply, circulating, burned := expected.SiacoinSupply, expected.CirculatingSupply, expected.BurnedSiacoins
 	n := 1000
 	for i := 0; i < n; i++ {
 		sendAmount := types.Siacoins(7).Div64(1000)
@@ -205,13 +279,22 @@ func TestChainStatsSiacoins(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		// the wallet pays no fee, and both the sent coins and the block
+		// reward are burned
+		reward := blockReward(cm.Tip().Height)
+		supply = supply.Add(reward)
+		circulating = circulating.Sub(sendAmount)
+		burned = burned.Add(reward).Add(sendAmount)
+
 		stats, err := e.ChainStatsLatest()
 		if err != nil {
 			t.Fatal(err)
 		}
 		expected := explorer.ChainStats{
 			// don't compare these
-			Block: stats.Block,
+			Block:          stats.Block,
+			SiacoinHolders: stats.SiacoinHolders,
+			SiafundHolders: stats.SiafundHolders,
 
 			SpentSiacoinsCount:  1,
 			SpentSiafundsCount:  0,
@@ -221,6 +304,12 @@ func TestChainStatsSiacoins(t *testing.T) {
 			TotalContractCost:   types.Siacoins(825),
 			TotalContractSize:   0,
 			TotalRevisionVolume: 0,
+
+			SiacoinSupply:           supply,
+			CirculatingSupply:       circulating,
+			BurnedSiacoins:          burned,
+			SiafundPool:             genesisTax,
+			UnclaimedSiafundRevenue: genesisTax,
 		}
 		if !reflect.DeepEqual(stats, expected) {
 			t.Fatal("chainstats don't match")
@@ -243,7 +332,10 @@ func TestChainStatsContracts(t *testing.T) {
 		t.Fatal(err)
 	}
 	explorerStore := explorerutil.NewEphemeralStore()
-	e := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	if err != nil {
+		t.Fatal(err)
+	}
 	cm.AddSubscriber(e, cm.Tip())
 	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
 		t.Fatal(err)
@@ -263,7 +355,7 @@ func TestChainStatsContracts(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	renterOutputs, err := e.UnspentSiacoinElements(types.StandardAddress(renterPubkey))
+	renterOutputs, _, err := e.UnspentSiacoinElements(types.StandardAddress(renterPubkey), "", 100)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -272,7 +364,7 @@ func TestChainStatsContracts(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hostOutputs, err := e.UnspentSiacoinElements(types.StandardAddress(hostPubkey))
+	hostOutputs, _, err := e.UnspentSiacoinElements(types.StandardAddress(hostPubkey), "", 100)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -331,7 +423,9 @@ func TestChainStatsContracts(t *testing.T) {
 	}
 	expected := explorer.ChainStats{
 		// don't compare these
-		Block: stats.Block,
+		Block:          stats.Block,
+		SiacoinHolders: stats.SiacoinHolders,
+		SiafundHolders: stats.SiafundHolders,
 
 		SpentSiacoinsCount:  2,
 		SpentSiafundsCount:  0,
@@ -341,12 +435,211 @@ func TestChainStatsContracts(t *testing.T) {
 		TotalContractCost:   types.Siacoins(825).Add(types.Siacoins(77)),
 		TotalContractSize:   0,
 		TotalRevisionVolume: 0,
+
+		// the renter and host spent 107 SC on a contract worth 77 SC, its
+		// tax of 3.08 SC, and a miner fee of 26.92 SC, which is destroyed
+		SiacoinSupply:           genesisSupply.Add(blockReward(1)).Add(blockReward(2)).Sub(types.Siacoins(2692).Div64(100)),
+		CirculatingSupply:       genesisCirculating.Sub(types.Siacoins(107)),
+		BurnedSiacoins:          genesisBurned.Add(blockReward(1)).Add(blockReward(2)),
+		SiafundPool:             genesisTax.Add(types.Siacoins(308).Div64(100)),
+		UnclaimedSiafundRevenue: genesisTax.Add(types.Siacoins(308).Div64(100)),
 	}
 	if !reflect.DeepEqual(stats, expected) {
 		t.Fatal("chainstats don't match")
 	}
 }
 
+func TestBlocks(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	blocks := append([]types.Block{sim.Genesis.Block}, sim.MineBlocks(5)...)
+	for _, b := range blocks[1:] {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, b := range blocks {
+		byHeight, err := e.BlockByHeight(b.Header.Height)
+		if err != nil {
+			t.Fatal(err)
+		} else if byHeight.ID() != b.ID() {
+			t.Fatal("wrong block returned for height", b.Header.Height)
+		}
+		byID, err := e.BlockByID(b.ID())
+		if err != nil {
+			t.Fatal(err)
+		} else if byID.ID() != b.ID() || len(byID.Transactions) != len(b.Transactions) {
+			t.Fatal("wrong block returned for ID", b.ID())
+		}
+	}
+
+	if _, err := e.BlockByHeight(uint64(len(blocks))); err == nil {
+		t.Fatal("expected error for block above tip")
+	}
+}
+
+func TestSpentElements(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	pubkey, _ := testingKeypair(1)
+	b := sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(1), Address: types.StandardAddress(pubkey)})
+	if err := cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	txn := b.Transactions[0]
+
+	// inputs should still be retrievable, and marked as spent
+	for _, in := range txn.SiacoinInputs {
+		if _, err := e.SiacoinElement(in.Parent.ID); err != nil {
+			t.Fatal(err)
+		}
+		status, err := e.ElementStatus(in.Parent.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if !status.Spent || status.SpentBy != txn.ID() || status.SpentHeight != b.Header.Height {
+			t.Fatal("input should be spent by transaction", status)
+		}
+	}
+	if status, err := e.ElementStatus(txn.SiacoinOutputID(0)); err != nil {
+		t.Fatal(err)
+	} else if status.Spent {
+		t.Fatal("output should be unspent")
+	}
+
+	// trace forward from an input to the transaction that spent it
+	steps, err := e.Trace(txn.SiacoinInputs[0].Parent.ID, true, 5)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(steps) != 1 || steps[0].Transaction != txn.ID() || steps[0].Height != b.Header.Height {
+		t.Fatal("wrong forward trace", steps)
+	} else if len(steps[0].Outputs) != len(txn.SiacoinOutputs) || steps[0].Outputs[0] != txn.SiacoinOutputID(0) {
+		t.Fatal("wrong outputs in forward trace", steps[0].Outputs)
+	}
+
+	// trace backward from an output, through the transaction, to the genesis
+	// transaction that created its inputs
+	steps, err = e.Trace(txn.SiacoinOutputID(0), false, 5)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(steps) != 2 || steps[0].Transaction != txn.ID() || steps[1].Transaction != sim.Genesis.Block.Transactions[0].ID() {
+		t.Fatal("wrong backward trace", steps)
+	}
+}
+
+func TestBalanceHistory(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	pubkey, _ := testingKeypair(1)
+	addr := types.StandardAddress(pubkey)
+	var heights []uint64
+	for _, n := range []uint32{10, 5} {
+		b := sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(n), Address: addr})
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		heights = append(heights, b.Header.Height)
+		// an unrelated block in between
+		if err := cm.AddTipBlock(sim.MineBlockWithTxns()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := e.BalanceHistory(addr, 0, cm.Tip().Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []explorer.HistoricalBalance{
+		{Height: heights[0], Siacoins: types.Siacoins(10)},
+		{Height: heights[1], Siacoins: types.Siacoins(15)},
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Fatal("wrong balance history", history)
+	}
+
+	// the materialized balance should agree with the history
+	if sc, sf, err := e.AddressBalance(addr); err != nil {
+		t.Fatal(err)
+	} else if !sc.Equals(expected[1].Siacoins) || sf != 0 {
+		t.Fatal("wrong balance", sc, sf)
+	}
+
+	history, err = e.BalanceHistory(addr, heights[1], heights[1])
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(history, expected[1:]) {
+		t.Fatal("wrong balance history for range", history)
+	}
+
+	history, err = e.BalanceHistory(addr, 0, heights[0]-1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(history) != 0 {
+		t.Fatal("expected no history before first deposit", history)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	// send to the same address in 5 blocks, twice in the last one
+	pubkey, _ := testingKeypair(1)
+	addr := types.StandardAddress(pubkey)
+	var txids []types.TransactionID
+	var elems []types.ElementID
+	for i := 0; i < 5; i++ {
+		var txns []types.Transaction
+		for j := 0; j < 1+i/4; j++ {
+			txns = append(txns, sim.TxnWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(1), Address: addr}))
+		}
+		if err := cm.AddTipBlock(sim.MineBlockWithTxns(txns...)); err != nil {
+			t.Fatal(err)
+		}
+		for _, txn := range txns {
+			txids = append([]types.TransactionID{txn.ID()}, txids...)
+			elems = append([]types.ElementID{txn.SiacoinOutputID(0)}, elems...)
+		}
+	}
+
+	var gotTxids []types.TransactionID
+	var cursor explorer.Cursor
+	for {
+		ids, next, err := e.Transactions(addr, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotTxids = append(gotTxids, ids...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(gotTxids, txids) {
+		t.Fatal("transactions should be listed newest first", gotTxids, txids)
+	}
+
+	var gotElems []types.ElementID
+	cursor = ""
+	for {
+		ids, next, err := e.UnspentSiacoinElements(addr, cursor, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotElems = append(gotElems, ids...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(gotElems, elems) {
+		t.Fatal("unspent elements should be listed newest first", gotElems, elems)
+	}
+
+	if _, _, err := e.Transactions(addr, "not a cursor", 2); !errors.Is(err, explorer.ErrInvalidCursor) {
+		t.Fatal("expected ErrInvalidCursor, got", err)
+	}
+}
+
 var genesis consensus.State
 var benchUpdates []*chain.ApplyUpdate
 
@@ -359,14 +652,14 @@ func BenchmarkAddEmptyBlocks(b *testing.B) {
 		genesis = sim.Genesis.State
 
 		sau := chain.ApplyUpdate{
-			consensus.GenesisUpdate(sim.Genesis.Block, types.Work{NumHashes: [32]byte{31: 4}}),
-			sim.Genesis.Block,
+			ApplyUpdate: consensus.GenesisUpdate(sim.Genesis.Block, types.Work{NumHashes: [32]byte{31: 4}}),
+			Block:       sim.Genesis.Block,
 		}
 		benchUpdates = append(benchUpdates, &sau)
 
 		cs := sim.Genesis.State
 		for _, block := range sim.Chain {
-			sau := chain.ApplyUpdate{consensus.ApplyBlock(cs, block), block}
+			sau := chain.ApplyUpdate{ApplyUpdate: consensus.ApplyBlock(cs, block), Block: block}
 			benchUpdates = append(benchUpdates, &sau)
 			cs = sau.State
 		}
@@ -381,7 +674,10 @@ func BenchmarkAddEmptyBlocks(b *testing.B) {
 			b.Fatal(err)
 		}
 		explorerStore := explorerutil.NewEphemeralStore()
-		e := explorer.NewExplorer(genesis, explorerStore, hs)
+		e, err := explorer.NewExplorer(genesis, explorerStore, hs)
+		if err != nil {
+			b.Fatal(err)
+		}
 		b.StartTimer()
 		for _, cau := range benchUpdates {
 			if err := e.ProcessChainApplyUpdate(cau, false); err != nil {
@@ -402,7 +698,10 @@ func BenchmarkSiacoinElement(b *testing.B) {
 		b.Fatal(err)
 	}
 	explorerStore := explorerutil.NewEphemeralStore()
-	e := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	if err != nil {
+		b.Fatal(err)
+	}
 	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
 		b.Fatal(err)
 	}
@@ -434,7 +733,10 @@ func BenchmarkMerkleProof(b *testing.B) {
 		b.Fatal(err)
 	}
 	explorerStore := explorerutil.NewEphemeralStore()
-	e := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	e, err := explorer.NewExplorer(sim.Genesis.State, explorerStore, hs)
+	if err != nil {
+		b.Fatal(err)
+	}
 	if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
 		b.Fatal(err)
 	}
@@ -457,3 +759,1266 @@ func BenchmarkMerkleProof(b *testing.B) {
 		}
 	}
 }
+
+// A contractWatcher tracks a file contract element and keeps its proof up to
+// date.
+type contractWatcher struct {
+	id  types.ElementID
+	fce types.FileContractElement
+}
+
+func (cw *contractWatcher) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, _ bool) error {
+	if cw.fce.ID == cw.id {
+		cau.UpdateElementProof(&cw.fce.StateElement)
+	}
+	for _, fce := range cau.NewFileContracts {
+		if fce.ID == cw.id {
+			cw.fce = fce
+		}
+	}
+	for _, fce := range cau.RevisedFileContracts {
+		if fce.ID == cw.id {
+			cw.fce.FileContract = fce.FileContract
+		}
+	}
+	return nil
+}
+
+func (cw *contractWatcher) ProcessChainRevertUpdate(cru *chain.RevertUpdate) error {
+	return nil
+}
+
+// A windowWatcher tracks the storage proof window of contracts whose proof
+// window starts at height.
+type windowWatcher struct {
+	height uint64
+	sp     types.StorageProof
+}
+
+func (ww *windowWatcher) ProcessChainApplyUpdate(cau *chain.ApplyUpdate, _ bool) error {
+	if cau.Block.Header.Height == ww.height {
+		ww.sp.WindowStart = cau.Block.Index()
+		ww.sp.WindowProof = cau.HistoryProof()
+	} else if cau.Block.Header.Height > ww.height {
+		cau.UpdateWindowProof(&ww.sp)
+	}
+	return nil
+}
+
+func (ww *windowWatcher) ProcessChainRevertUpdate(cru *chain.RevertUpdate) error {
+	return nil
+}
+
+func TestContracts(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	renterPubkey, renterPrivkey := testingKeypair(1)
+	hostPubkey, hostPrivkey := testingKeypair(2)
+	if err := cm.AddTipBlock(sim.MineBlockWithSiacoinOutputs(
+		types.SiacoinOutput{Value: types.Siacoins(100), Address: types.StandardAddress(renterPubkey)},
+		types.SiacoinOutput{Value: types.Siacoins(7), Address: types.StandardAddress(hostPubkey)},
+	)); err != nil {
+		t.Fatal(err)
+	}
+	spendable := func(pubkey types.PublicKey) types.SiacoinElement {
+		ids, _, err := e.UnspentSiacoinElements(types.StandardAddress(pubkey), "", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sce, err := e.SiacoinElement(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		} else if sce.MerkleProof, err = e.MerkleProof(sce.ID); err != nil {
+			t.Fatal(err)
+		}
+		return sce
+	}
+	renterOutput, hostOutput := spendable(renterPubkey), spendable(hostPubkey)
+	signContract := func(fc *types.FileContract) {
+		contractHash := cm.TipState().ContractSigHash(*fc)
+		fc.RenterSignature = renterPrivkey.SignHash(contractHash)
+		fc.HostSignature = hostPrivkey.SignHash(contractHash)
+	}
+
+	// form contract
+	initialRev := types.FileContract{
+		WindowStart: 5,
+		WindowEnd:   10,
+		RenterOutput: types.SiacoinOutput{
+			Address: types.StandardAddress(renterPubkey),
+			Value:   types.Siacoins(58),
+		},
+		HostOutput: types.SiacoinOutput{
+			Address: types.StandardAddress(hostPubkey),
+			Value:   types.Siacoins(19),
+		},
+		MissedHostValue: types.Siacoins(17),
+		TotalCollateral: types.Siacoins(18),
+		RenterPublicKey: renterPubkey,
+		HostPublicKey:   hostPubkey,
+	}
+	outputSum := initialRev.RenterOutput.Value.Add(initialRev.HostOutput.Value).Add(cm.TipState().FileContractTax(initialRev))
+	formation := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{
+			{Parent: renterOutput, SpendPolicy: types.PolicyPublicKey(renterPubkey)},
+			{Parent: hostOutput, SpendPolicy: types.PolicyPublicKey(hostPubkey)},
+		},
+		FileContracts: []types.FileContract{initialRev},
+		MinerFee:      renterOutput.Value.Add(hostOutput.Value).Sub(outputSum),
+	}
+	signContract(&formation.FileContracts[0])
+	sigHash := cm.TipState().InputSigHash(formation)
+	formation.SiacoinInputs[0].Signatures = []types.Signature{renterPrivkey.SignHash(sigHash)}
+	formation.SiacoinInputs[1].Signatures = []types.Signature{hostPrivkey.SignHash(sigHash)}
+	fcid := types.ElementID{Source: types.Hash256(formation.ID()), Index: 0}
+	cw := &contractWatcher{id: fcid}
+	cm.AddSubscriber(cw, cm.Tip())
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(formation)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkIndex := func(status string) {
+		t.Helper()
+		for _, s := range []string{"", explorer.ContractStatusActive, explorer.ContractStatusResolved, explorer.ContractStatusMissed} {
+			hostIDs, _, err := e.HostContracts(hostPubkey, s, "", 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			renterIDs, _, err := e.RenterContracts(renterPubkey, s, "", 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp := 0
+			if s == "" || s == status {
+				exp = 1
+			}
+			if len(hostIDs) != exp || len(renterIDs) != exp {
+				t.Fatalf("expected %v %q contracts, got %v host and %v renter contracts", exp, s, len(hostIDs), len(renterIDs))
+			} else if exp == 1 && (hostIDs[0] != fcid || renterIDs[0] != fcid) {
+				t.Fatal("wrong contract ID")
+			}
+		}
+	}
+	checkHostStats := func(expected explorer.HostStats) {
+		t.Helper()
+		hs, err := e.HostStats(hostPubkey)
+		if err != nil {
+			t.Fatal(err)
+		} else if hs != expected {
+			t.Fatalf("expected host stats %+v, got %+v", expected, hs)
+		}
+	}
+	expiring := func(from, to uint64) bool {
+		t.Helper()
+		fces, err := e.ContractsExpiring(from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, fce := range fces {
+			if fce.ID == fcid {
+				return true
+			}
+		}
+		return false
+	}
+	checkIndex(explorer.ContractStatusActive)
+	if !expiring(0, 5) || !expiring(10, 20) || expiring(6, 9) || expiring(11, 20) {
+		t.Fatal("contract should expire only at heights 5 and 10")
+	}
+	checkHostStats(explorer.HostStats{
+		Contracts:        1,
+		ActiveContracts:  1,
+		LockedCollateral: initialRev.TotalCollateral,
+	})
+
+	// revise contract
+	rev := initialRev
+	rev.RevisionNumber = 1
+	rev.Filesize = 4096
+	rev.FileMerkleRoot = types.Hash256{1}
+	rev.RenterOutput.Value = types.Siacoins(50)
+	rev.HostOutput.Value = types.Siacoins(27)
+	signContract(&rev)
+	revision := types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{Parent: cw.fce, Revision: rev}},
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(revision)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkHostStats(explorer.HostStats{
+		Contracts:        1,
+		ActiveContracts:  1,
+		StoredBytes:      4096,
+		LockedCollateral: initialRev.TotalCollateral,
+	})
+
+	// let the proof window expire, then resolve the contract as missed
+	for cm.Tip().Height <= rev.WindowEnd {
+		if err := cm.AddTipBlock(sim.MineBlock()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolution := types.Transaction{
+		FileContractResolutions: []types.FileContractResolution{{Parent: cw.fce}},
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(resolution)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkIndex(explorer.ContractStatusMissed)
+	if expiring(0, 20) {
+		t.Fatal("resolved contract should not be expiring")
+	}
+	checkHostStats(explorer.HostStats{
+		Contracts:         1,
+		MissedResolutions: 1,
+	})
+	if hs, _ := e.HostStats(hostPubkey); hs.ProofSuccessRate() != 0 {
+		t.Fatal("expected proof success rate of 0, got", hs.ProofSuccessRate())
+	} else if _, err := e.HostStats(renterPubkey); !errors.Is(err, explorer.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+
+	history, err := e.ContractHistory(fcid)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(history) != 3 {
+		t.Fatalf("expected 3 contract events, got %v", len(history))
+	}
+	formed, revised, resolved := history[0], history[1], history[2]
+	if formed.Type != explorer.ContractEventFormation || formed.Transaction != formation.ID() || formed.Contract.RevisionNumber != 0 {
+		t.Fatal("formation event doesn't match", formed)
+	} else if revised.Type != explorer.ContractEventRevision || revised.Transaction != revision.ID() || revised.Contract.RevisionNumber != 1 || revised.Contract.Filesize != 4096 {
+		t.Fatal("revision event doesn't match", revised)
+	} else if resolved.Type != explorer.ContractEventResolution || resolved.Transaction != resolution.ID() || resolved.Valid {
+		t.Fatal("resolution event doesn't match", resolved)
+	} else if resolved.RenterPayout != rev.RenterOutput || resolved.HostPayout != rev.MissedHostOutput() {
+		t.Fatal("resolution payouts don't match", resolved.RenterPayout, resolved.HostPayout)
+	}
+	for _, ev := range history {
+		if ev.ContractID != fcid {
+			t.Fatal("wrong contract ID", ev.ContractID)
+		}
+	}
+
+	if _, err := e.ContractHistory(types.ElementID{}); !errors.Is(err, explorer.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+
+	// form two more contracts: one proven with a storage proof, and one
+	// finalized, which does not count towards the proof success rate
+	funderPubkey, funderPrivkey := testingKeypair(3)
+	if err := cm.AddTipBlock(sim.MineBlockWithSiacoinOutputs(
+		types.SiacoinOutput{Value: types.Siacoins(200), Address: types.StandardAddress(funderPubkey)},
+	)); err != nil {
+		t.Fatal(err)
+	}
+	funds := spendable(funderPubkey)
+	var leaf [64]byte
+	leaf[0] = 1
+	proven := initialRev
+	proven.Filesize = uint64(len(leaf))
+	proven.FileMerkleRoot = merkle.StorageProofLeafHash(leaf[:])
+	proven.WindowStart = cm.Tip().Height + 3
+	proven.WindowEnd = cm.Tip().Height + 5
+	finalized := initialRev
+	finalized.WindowStart = proven.WindowStart
+	finalized.WindowEnd = proven.WindowEnd
+	outputSum = proven.RenterOutput.Value.Add(proven.HostOutput.Value).Add(cm.TipState().FileContractTax(proven)).
+		Add(finalized.RenterOutput.Value).Add(finalized.HostOutput.Value).Add(cm.TipState().FileContractTax(finalized))
+	formation = types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{Parent: funds, SpendPolicy: types.PolicyPublicKey(funderPubkey)}},
+		FileContracts: []types.FileContract{proven, finalized},
+		MinerFee:      funds.Value.Sub(outputSum),
+	}
+	signContract(&formation.FileContracts[0])
+	signContract(&formation.FileContracts[1])
+	sigHash = cm.TipState().InputSigHash(formation)
+	formation.SiacoinInputs[0].Signatures = []types.Signature{funderPrivkey.SignHash(sigHash)}
+	provenWatcher := &contractWatcher{id: types.ElementID{Source: types.Hash256(formation.ID()), Index: 0}}
+	finalizedWatcher := &contractWatcher{id: types.ElementID{Source: types.Hash256(formation.ID()), Index: 1}}
+	ww := &windowWatcher{height: proven.WindowStart}
+	cm.AddSubscriber(provenWatcher, cm.Tip())
+	cm.AddSubscriber(finalizedWatcher, cm.Tip())
+	cm.AddSubscriber(ww, cm.Tip())
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(formation)); err != nil {
+		t.Fatal(err)
+	}
+
+	final := finalizedWatcher.fce.FileContract
+	final.RevisionNumber = types.MaxRevisionNumber
+	signContract(&final)
+	finalization := types.Transaction{
+		FileContractResolutions: []types.FileContractResolution{{Parent: finalizedWatcher.fce, Finalization: final}},
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(finalization)); err != nil {
+		t.Fatal(err)
+	}
+	for cm.Tip().Height < proven.WindowStart {
+		if err := cm.AddTipBlock(sim.MineBlockWithTxns()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ww.sp.Leaf = leaf
+	proof := types.Transaction{
+		FileContractResolutions: []types.FileContractResolution{{Parent: provenWatcher.fce, StorageProof: ww.sp}},
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(proof)); err != nil {
+		t.Fatal(err)
+	}
+	checkHostStats(explorer.HostStats{
+		Contracts:         3,
+		ValidResolutions:  2,
+		MissedResolutions: 1,
+		StorageProofs:     1,
+	})
+	if hs, _ := e.HostStats(hostPubkey); hs.ProofSuccessRate() != 0.5 {
+		t.Fatal("expected proof success rate of 0.5, got", hs.ProofSuccessRate())
+	}
+}
+
+func TestAttestations(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	pubkey, privkey := testingKeypair(1)
+	attest := func(kvs ...string) types.Transaction {
+		var txn types.Transaction
+		for i := 0; i < len(kvs); i += 2 {
+			a := types.Attestation{PublicKey: pubkey, Key: kvs[i], Value: []byte(kvs[i+1])}
+			a.Signature = privkey.SignHash(cm.TipState().AttestationSigHash(a))
+			txn.Attestations = append(txn.Attestations, a)
+		}
+		return txn
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(attest("host", "foo"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithTxns(attest("other", "bar", "host", "baz"))); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := e.LatestAttestations(pubkey)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(latest) != 2 {
+		t.Fatalf("expected 2 latest attestations, got %v", len(latest))
+	} else if a := latest[0].Attestation; a.Key != "host" || string(a.Value) != "baz" {
+		t.Fatalf("expected host=baz, got %v=%s", a.Key, a.Value)
+	} else if a := latest[1].Attestation; a.Key != "other" || string(a.Value) != "bar" {
+		t.Fatalf("expected other=bar, got %v=%s", a.Key, a.Value)
+	} else if latest[0].Index != cm.Tip() {
+		t.Fatal("wrong attestation index", latest[0].Index)
+	}
+
+	// page through the history of a single key
+	var values []string
+	var cursor explorer.Cursor
+	for {
+		atts, next, err := e.Attestations(pubkey, "host", cursor, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range atts {
+			values = append(values, string(a.Attestation.Value))
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(values, []string{"baz", "foo"}) {
+		t.Fatal("wrong attestation history", values)
+	}
+
+	all, _, err := e.Attestations(pubkey, "", "", 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(all) != 3 {
+		t.Fatalf("expected 3 attestations, got %v", len(all))
+	}
+}
+
+func TestArbitraryData(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	payloads := [][]byte{
+		[]byte("foo:1"),
+		[]byte("foo:2"),
+		[]byte("bar"),
+		{0xff, 0xff, 0x01},
+		append([]byte("long:"), make([]byte, 2*explorer.ArbitraryDataPrefixSize)...),
+	}
+	ids := make(map[string]types.TransactionID)
+	for _, data := range payloads {
+		txn := types.Transaction{ArbitraryData: data}
+		if err := cm.AddTipBlock(sim.MineBlockWithTxns(txn)); err != nil {
+			t.Fatal(err)
+		}
+		ids[string(data)] = txn.ID()
+	}
+
+	tests := []struct {
+		prefix string
+		exp    []string
+	}{
+		{"foo", []string{"foo:2", "foo:1"}},
+		{"fo", []string{"foo:2", "foo:1"}},
+		{"foo:1", []string{"foo:1"}},
+		{"\xff\xff", []string{"\xff\xff\x01"}},
+		{"long:", []string{string(payloads[4])}},
+		{"baz", nil},
+	}
+	for _, test := range tests {
+		var got []types.TransactionID
+		var cursor explorer.Cursor
+		for {
+			page, next, err := e.ArbitraryDataTransactions([]byte(test.prefix), cursor, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		var exp []types.TransactionID
+		for _, data := range test.exp {
+			exp = append(exp, ids[data])
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("prefix %q: expected %v, got %v", test.prefix, exp, got)
+		}
+	}
+}
+
+func TestMinerPayouts(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	for i := 0; i < 5; i++ {
+		reward := cm.TipState().BlockReward()
+		b := sim.MineBlock()
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		var fees types.Currency
+		for _, txn := range b.Transactions {
+			fees = fees.Add(txn.MinerFee)
+		}
+		mp, err := e.MinerPayout(b.Header.Height)
+		if err != nil {
+			t.Fatal(err)
+		} else if mp.Index != b.Index() || mp.Address != b.Header.MinerAddress {
+			t.Fatal("wrong block", mp.Index, mp.Address)
+		} else if mp.Subsidy != reward || mp.Fees != fees {
+			t.Fatalf("expected subsidy %v and fees %v, got %v and %v", reward, fees, mp.Subsidy, mp.Fees)
+		}
+		miner, err := e.SiacoinElement(b.MinerOutputID())
+		if err != nil {
+			t.Fatal(err)
+		} else if mp.Payout != miner.Value {
+			t.Fatalf("expected payout %v, got %v", miner.Value, mp.Payout)
+		}
+	}
+
+	var heights []uint64
+	var cursor explorer.Cursor
+	for {
+		payouts, next, err := e.MinerBlocks(types.VoidAddress, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mp := range payouts {
+			heights = append(heights, mp.Index.Height)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(heights, []uint64{5, 4, 3, 2, 1, 0}) {
+		t.Fatal("wrong miner blocks", heights)
+	}
+
+	shares, err := e.MinerShares(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(shares) != 1 || shares[0].Address != types.VoidAddress || shares[0].Blocks != 3 || shares[0].Share != 1 {
+		t.Fatalf("wrong miner shares %+v", shares)
+	}
+}
+
+func TestSupplyStats(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	if stats, err := e.ChainStatsLatest(); err != nil {
+		t.Fatal(err)
+	} else if stats.SiacoinSupply != genesisSupply || stats.BurnedSiacoins != genesisBurned || stats.UnclaimedSiafundRevenue != genesisTax {
+		t.Fatal("genesis supply stats don't match")
+	}
+
+	// mine a block, checking that the supply changes as expected; lost is the
+	// collateral burned by missed storage proofs, which is not sent to the
+	// void address
+	var lost types.Currency
+	mine := func(b types.Block, issued, burned, unclaimed types.Currency) {
+		t.Helper()
+		prev, err := e.ChainStatsLatest()
+		if err != nil {
+			t.Fatal(err)
+		} else if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		stats, err := e.ChainStatsLatest()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exp := prev.SiacoinSupply.Add(issued); stats.SiacoinSupply != exp {
+			t.Fatalf("expected supply %v, got %v", exp, stats.SiacoinSupply)
+		} else if exp := prev.BurnedSiacoins.Add(burned); stats.BurnedSiacoins != exp {
+			t.Fatalf("expected %v burned, got %v", exp, stats.BurnedSiacoins)
+		} else if stats.UnclaimedSiafundRevenue != unclaimed {
+			t.Fatalf("expected %v unclaimed, got %v", unclaimed, stats.UnclaimedSiafundRevenue)
+		} else if sum := stats.CirculatingSupply.Add(stats.BurnedSiacoins).Add(stats.ActiveContractCost).Add(stats.UnclaimedSiafundRevenue); sum != stats.SiacoinSupply {
+			t.Fatalf("supply %v does not equal sum of its parts %v", stats.SiacoinSupply, sum)
+		} else if stats.SiafundPool != cm.TipState().SiafundPool {
+			t.Fatalf("expected siafund pool %v, got %v", cm.TipState().SiafundPool, stats.SiafundPool)
+		}
+		if void, err := e.SiacoinBalance(types.VoidAddress); err != nil {
+			t.Fatal(err)
+		} else if void.Add(lost) != stats.BurnedSiacoins {
+			t.Fatalf("expected %v burned, got %v", void.Add(lost), stats.BurnedSiacoins)
+		}
+	}
+
+	w := walletutil.NewTestingWallet(cm.TipState())
+	cm.AddSubscriber(w, cm.Tip())
+	renterPubkey, renterPrivkey := testingKeypair(1)
+	hostPubkey, hostPrivkey := testingKeypair(2)
+	mine(sim.MineBlockWithSiacoinOutputs(
+		types.SiacoinOutput{Value: types.Siacoins(100), Address: w.NewAddress()},
+		types.SiacoinOutput{Value: types.Siacoins(100), Address: types.StandardAddress(renterPubkey)},
+		types.SiacoinOutput{Value: types.Siacoins(7), Address: types.StandardAddress(hostPubkey)},
+	), blockReward(1), blockReward(1), genesisTax)
+
+	// form a contract, paying the tax into the siafund pool
+	spendable := func(pubkey types.PublicKey) types.SiacoinElement {
+		ids, _, err := e.UnspentSiacoinElements(types.StandardAddress(pubkey), "", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sce, err := e.SiacoinElement(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		} else if sce.MerkleProof, err = e.MerkleProof(sce.ID); err != nil {
+			t.Fatal(err)
+		}
+		return sce
+	}
+	renterOutput, hostOutput := spendable(renterPubkey), spendable(hostPubkey)
+	fc := types.FileContract{
+		Filesize:       4096,
+		FileMerkleRoot: types.Hash256{1},
+		WindowStart:    cm.Tip().Height + 3,
+		WindowEnd:      cm.Tip().Height + 4,
+		RenterOutput: types.SiacoinOutput{
+			Address: types.StandardAddress(renterPubkey),
+			Value:   types.Siacoins(58),
+		},
+		HostOutput: types.SiacoinOutput{
+			Address: types.StandardAddress(hostPubkey),
+			Value:   types.Siacoins(19),
+		},
+		MissedHostValue: types.Siacoins(17),
+		TotalCollateral: types.Siacoins(18),
+		RenterPublicKey: renterPubkey,
+		HostPublicKey:   hostPubkey,
+	}
+	contractHash := cm.TipState().ContractSigHash(fc)
+	fc.RenterSignature = renterPrivkey.SignHash(contractHash)
+	fc.HostSignature = hostPrivkey.SignHash(contractHash)
+	outputSum := fc.RenterOutput.Value.Add(fc.HostOutput.Value).Add(cm.TipState().FileContractTax(fc))
+	formation := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{
+			{Parent: renterOutput, SpendPolicy: types.PolicyPublicKey(renterPubkey)},
+			{Parent: hostOutput, SpendPolicy: types.PolicyPublicKey(hostPubkey)},
+		},
+		FileContracts: []types.FileContract{fc},
+		MinerFee:      renterOutput.Value.Add(hostOutput.Value).Sub(outputSum),
+	}
+	sigHash := cm.TipState().InputSigHash(formation)
+	formation.SiacoinInputs[0].Signatures = []types.Signature{renterPrivkey.SignHash(sigHash)}
+	formation.SiacoinInputs[1].Signatures = []types.Signature{hostPrivkey.SignHash(sigHash)}
+	cw := &contractWatcher{id: types.ElementID{Source: types.Hash256(formation.ID()), Index: 0}}
+	cm.AddSubscriber(cw, cm.Tip())
+	// the renter and host spent 107 SC on a contract worth 77 SC and its
+	// 3.08 SC tax; the remaining 26.92 SC miner fee is destroyed
+	unclaimed := genesisTax.Add(types.Siacoins(308).Div64(100))
+	mine(sim.MineBlockWithTxns(formation), blockReward(2).Sub(types.Siacoins(2692).Div64(100)), blockReward(2), unclaimed)
+
+	// the simulator mines to the void address
+	for i := 0; i < 3; i++ {
+		reward := blockReward(cm.Tip().Height + 1)
+		mine(sim.MineBlockWithTxns(), reward, reward, unclaimed)
+	}
+	for i := 0; i < 3; i++ {
+		txn := types.Transaction{
+			SiacoinOutputs: []types.SiacoinOutput{{Address: types.VoidAddress, Value: types.Siacoins(1)}},
+			MinerFee:       types.Siacoins(1).Div64(10),
+		}
+		if err := w.FundAndSign(&txn); err != nil {
+			t.Fatal(err)
+		}
+		reward := blockReward(cm.Tip().Height + 1)
+		mine(sim.MineBlockWithTxns(txn), reward.Sub(txn.MinerFee), reward.Add(types.Siacoins(1)), unclaimed)
+	}
+
+	// claim the siafund pool and finalize some of the genesis contracts; the
+	// simulator claims to the void address, and its 550 siafunds are owed
+	// 0.0001 of the pool each. Its five siacoin outputs each pay a fee of 8H.
+	claim := types.Siacoins(19844).Div64(10000)
+	reward := blockReward(cm.Tip().Height + 1)
+	mine(sim.MineBlock(), reward.Sub(types.NewCurrency64(5*8)), reward.Add(claim), unclaimed.Sub(claim))
+
+	// the proof window has expired; resolve the contract as missed, burning
+	// the difference between the host's valid and missed outputs
+	if cm.Tip().Height <= fc.WindowEnd {
+		t.Fatal("proof window should have expired")
+	}
+	resolution := types.Transaction{
+		FileContractResolutions: []types.FileContractResolution{{Parent: cw.fce}},
+	}
+	lost = types.Siacoins(2)
+	reward = blockReward(cm.Tip().Height + 1)
+	mine(sim.MineBlockWithTxns(resolution), reward, reward.Add(lost), unclaimed.Sub(claim))
+
+	series, err := e.SupplyStats(1, cm.Tip().Height, 2)
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := (cm.Tip().Height-1)/2 + 1; uint64(len(series)) != exp {
+		t.Fatalf("expected %v points, got %v", exp, len(series))
+	}
+	for i, ss := range series {
+		stats, err := e.ChainStats(ss.Index)
+		if err != nil {
+			t.Fatal(err)
+		} else if ss.Index.Height != uint64(1+2*i) {
+			t.Fatal("wrong height", ss.Index.Height)
+		} else if ss.SiacoinSupply != stats.SiacoinSupply || ss.LockedSiacoins != stats.ActiveContractCost || ss.UnclaimedSiafundRevenue != stats.UnclaimedSiafundRevenue {
+			t.Fatal("supply stats don't match chain stats")
+		}
+	}
+}
+
+func TestChainStatsRestart(t *testing.T) {
+	sim := chainutil.NewChainSim()
+	for i := 0; i < 3; i++ {
+		sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(10), Address: types.Address{byte(i + 1)}})
+	}
+
+	// process the first three blocks, then restart the explorer and process
+	// two more
+	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.Genesis.State)
+	for _, b := range sim.Chain {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hs, err := explorerutil.NewHashStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := explorerutil.NewEphemeralStore()
+	e, err := explorer.NewExplorer(sim.Genesis.State, store, hs)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
+		t.Fatal(err)
+	} else if err := cm.AddSubscriber(e, sim.Genesis.State.Index); err != nil {
+		t.Fatal(err)
+	}
+	tip := cm.TipState()
+	for i := 0; i < 2; i++ {
+		sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Value: types.Siacoins(10), Address: types.Address{byte(i + 4)}})
+	}
+	cm = chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.Genesis.State)
+	for _, b := range sim.Chain {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	restarted, err := explorer.NewExplorer(tip, store, hs)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := cm.AddSubscriber(restarted, tip.Index); err != nil {
+		t.Fatal(err)
+	}
+
+	// the restarted explorer should agree with one that processed every block
+	if hs, err = explorerutil.NewHashStore(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	e, err = explorer.NewExplorer(sim.Genesis.State, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := addGenesisElements(e, sim.Genesis.Block); err != nil {
+		t.Fatal(err)
+	} else if err := cm.AddSubscriber(e, sim.Genesis.State.Index); err != nil {
+		t.Fatal(err)
+	}
+	if exp, err := e.ChainStatsLatest(); err != nil {
+		t.Fatal(err)
+	} else if stats, err := restarted.ChainStatsLatest(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(stats, exp) {
+		t.Fatalf("expected stats %v after restart, got %v", exp, stats)
+	}
+}
+
+func TestNetworkMetrics(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	states := make(map[uint64]consensus.State)
+	timestamps := make(map[uint64]time.Time)
+	for _, b := range sim.MineBlocks(10) {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		states[b.Header.Height] = cm.TipState()
+		timestamps[b.Header.Height] = b.Header.Timestamp
+	}
+
+	metrics, err := e.BlockMetrics(3, 8)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(metrics) != 6 {
+		t.Fatalf("expected 6 blocks, got %v", len(metrics))
+	}
+	for i, bm := range metrics {
+		height := uint64(3 + i)
+		s := states[height]
+		if bm.Index != s.Index {
+			t.Fatal("wrong index", bm.Index)
+		} else if bm.Difficulty != s.Difficulty || bm.TotalWork != s.TotalWork {
+			t.Fatal("metrics don't match consensus state")
+		} else if !bm.Timestamp.Equal(timestamps[height]) {
+			t.Fatal("wrong timestamp", bm.Timestamp)
+		}
+	}
+
+	nm, err := e.NetworkMetrics(3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, last := states[3], states[8]
+	elapsed := timestamps[8].Sub(timestamps[3])
+	if nm.Start != first.Index || nm.End != last.Index {
+		t.Fatal("wrong range", nm.Start, nm.End)
+	} else if nm.Difficulty != last.Difficulty || nm.TotalWork != last.TotalWork {
+		t.Fatal("wrong difficulty or total work")
+	} else if nm.AverageBlockTime != elapsed/5 {
+		t.Fatalf("expected average block time %v, got %v", elapsed/5, nm.AverageBlockTime)
+	} else if exp := last.TotalWork.Sub(first.TotalWork).Div64(uint64(elapsed / time.Second)); nm.Hashrate != exp {
+		t.Fatalf("expected hashrate %v, got %v", exp, nm.Hashrate)
+	}
+
+	if _, err := e.NetworkMetrics(3, 20); err != explorer.ErrNotFound {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+}
+
+func TestFeeStats(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	w := walletutil.NewTestingWallet(cm.TipState())
+	cm.AddSubscriber(w, cm.Tip())
+	fund := types.SiacoinOutput{Value: types.Siacoins(100), Address: w.NewAddress()}
+	if err := cm.AddTipBlock(sim.MineBlockWithSiacoinOutputs(fund, fund, fund)); err != nil {
+		t.Fatal(err)
+	}
+
+	// mine a block containing transactions paying a range of fees
+	var txns []types.Transaction
+	for _, fee := range []uint32{3, 1, 2} {
+		txn := types.Transaction{
+			SiacoinOutputs: []types.SiacoinOutput{{Address: types.VoidAddress, Value: types.Siacoins(1)}},
+			MinerFee:       types.Siacoins(fee),
+		}
+		if err := w.FundAndSign(&txn); err != nil {
+			t.Fatal(err)
+		}
+		txns = append(txns, txn)
+	}
+	b := sim.MineBlockWithTxns(txns...)
+	if err := cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	} else if err := cm.AddTipBlock(sim.MineBlockWithTxns()); err != nil {
+		t.Fatal(err)
+	}
+
+	rate := func(txn types.Transaction) types.Currency {
+		return txn.MinerFee.Div64(uint64(types.EncodedLen(txn)))
+	}
+	stats, err := e.FeeStats(b.Header.Height, cm.Tip().Height)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 2 {
+		t.Fatalf("expected 2 blocks, got %v", len(stats))
+	}
+	exp := explorer.FeeStats{
+		Index:         b.Index(),
+		Transactions:  3,
+		TotalFees:     types.Siacoins(6),
+		MinFee:        types.Siacoins(1),
+		MedianFee:     types.Siacoins(2),
+		MaxFee:        types.Siacoins(3),
+		MinFeeRate:    rate(txns[1]),
+		MedianFeeRate: rate(txns[2]),
+		MaxFeeRate:    rate(txns[0]),
+	}
+	if !reflect.DeepEqual(stats[0], exp) {
+		t.Fatalf("expected %v, got %v", exp, stats[0])
+	} else if empty := (explorer.FeeStats{Index: cm.Tip()}); !reflect.DeepEqual(stats[1], empty) {
+		t.Fatalf("expected %v, got %v", empty, stats[1])
+	}
+
+	// empty blocks should not affect the estimate, and the backlog should
+	// only ever raise it
+	fe := explorer.EstimateFee(stats, nil)
+	if fe.Low != exp.MinFeeRate || fe.Medium != exp.MedianFeeRate || fe.High != fe.Medium {
+		t.Fatal("wrong estimate", fe)
+	}
+	pool := []types.Transaction{{MinerFee: types.Siacoins(1000)}}
+	fe = explorer.EstimateFee(stats, pool)
+	if fe.High != rate(pool[0]) || fe.Medium != exp.MedianFeeRate {
+		t.Fatal("wrong estimate with backlog", fe)
+	} else if fe.PoolTransactions != 1 || fe.PoolSize != uint64(types.EncodedLen(pool[0])) {
+		t.Fatal("wrong pool stats", fe)
+	}
+}
+
+func TestRichList(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	addrs := []types.Address{{1}, {2}, {3}}
+	b := sim.MineBlockWithSiacoinOutputs(
+		types.SiacoinOutput{Address: addrs[0], Value: types.Siacoins(5)},
+		types.SiacoinOutput{Address: addrs[1], Value: types.Siacoins(10)},
+		types.SiacoinOutput{Address: addrs[2], Value: types.Siacoins(10)},
+	)
+	if err := cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// page through the list, checking that it is ordered and consistent with
+	// each address's rank
+	list := func(richList func(explorer.Cursor, int) ([]explorer.RankedBalance, explorer.Cursor, error), less func(a, b explorer.RankedBalance) bool) []explorer.RankedBalance {
+		t.Helper()
+		var all []explorer.RankedBalance
+		var cursor explorer.Cursor
+		for {
+			page, next, err := richList(cursor, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		for i, rb := range all {
+			if rb.Rank != uint64(i+1) {
+				t.Fatalf("expected rank %v, got %v", i+1, rb.Rank)
+			} else if i > 0 && less(all[i-1], rb) {
+				t.Fatal("rich list is not ordered")
+			}
+		}
+		return all
+	}
+	scList := list(e.SiacoinRichList, func(a, b explorer.RankedBalance) bool { return a.Siacoins.Cmp(b.Siacoins) < 0 })
+	sfList := list(e.SiafundRichList, func(a, b explorer.RankedBalance) bool { return a.Siafunds < b.Siafunds })
+	if len(sfList) == 0 {
+		t.Fatal("expected siafund holders")
+	}
+
+	ranks, sfRanks := make(map[types.Address]uint64), make(map[types.Address]uint64)
+	for _, rb := range scList {
+		ranks[rb.Address] = rb.Rank
+	}
+	for _, rb := range sfList {
+		sfRanks[rb.Address] = rb.Rank
+	}
+	// ties are broken by address
+	if ranks[addrs[1]]+1 != ranks[addrs[2]] || ranks[addrs[2]] >= ranks[addrs[0]] {
+		t.Fatal("wrong relative ranks", ranks[addrs[0]], ranks[addrs[1]], ranks[addrs[2]])
+	}
+	for _, rb := range append(scList, sfList...) {
+		ar, err := e.AddressRank(rb.Address)
+		if err != nil {
+			t.Fatal(err)
+		} else if ar.SiacoinHolders != uint64(len(scList)) || ar.SiafundHolders != uint64(len(sfList)) {
+			t.Fatal("wrong holder counts", ar)
+		} else if !rb.Siacoins.IsZero() && ar.SiacoinRank != ranks[rb.Address] {
+			t.Fatalf("expected siacoin rank %v, got %v", ranks[rb.Address], ar.SiacoinRank)
+		} else if rb.Siafunds != 0 && ar.SiafundRank != sfRanks[rb.Address] {
+			t.Fatalf("expected siafund rank %v, got %v", sfRanks[rb.Address], ar.SiafundRank)
+		}
+	}
+	if ar, err := e.AddressRank(addrs[1]); err != nil {
+		t.Fatal(err)
+	} else if ar.SiafundRank != 0 || ar.SiafundPercentile() != 0 {
+		t.Fatal("address without siafunds should be unranked", ar)
+	} else if exp := 100 * float64(ar.SiacoinHolders-ar.SiacoinRank+1) / float64(ar.SiacoinHolders); ar.SiacoinPercentile() != exp {
+		t.Fatalf("expected percentile %v, got %v", exp, ar.SiacoinPercentile())
+	}
+
+	// a page should resume after the last address of the previous page, even
+	// if addresses have since moved ahead of it
+	k := int(ranks[addrs[2]])
+	page, next, err := e.SiacoinRichList("", k)
+	if err != nil {
+		t.Fatal(err)
+	} else if page[k-1].Address != addrs[2] || next == "" {
+		t.Fatal("expected page to end with", addrs[2])
+	}
+	if err := cm.AddTipBlock(sim.MineBlockWithSiacoinOutputs(types.SiacoinOutput{Address: types.Address{4}, Value: types.Siacoins(20)})); err != nil {
+		t.Fatal(err)
+	}
+	rest, _, err := e.SiacoinRichList(next, len(scList))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rest) != len(scList)-k {
+		t.Fatalf("expected %v remaining addresses, got %v", len(scList)-k, len(rest))
+	}
+	for i, rb := range rest {
+		if rb != scList[k+i] {
+			t.Fatalf("expected %v, got %v", scList[k+i], rb)
+		}
+	}
+	if _, _, err := e.SiacoinRichList("foo", 1); err != explorer.ErrInvalidCursor {
+		t.Fatal("expected ErrInvalidCursor, got", err)
+	}
+}
+
+func TestSiafundClaims(t *testing.T) {
+	sim, cm, e := newTestExplorer(t)
+
+	w := walletutil.NewTestingWallet(cm.TipState())
+	cm.AddSubscriber(w, cm.Tip())
+	fund := types.SiacoinOutput{Value: types.Siacoins(100), Address: w.NewAddress()}
+	if err := cm.AddTipBlock(sim.MineBlockWithSiacoinOutputs(fund, fund)); err != nil {
+		t.Fatal(err)
+	}
+
+	// forming a contract adds its tax to the siafund pool
+	renterPubkey, renterPrivkey := testingKeypair(1)
+	hostPubkey, hostPrivkey := testingKeypair(2)
+	formContract := func() {
+		t.Helper()
+		fc := types.FileContract{
+			WindowStart:     cm.Tip().Height + 10,
+			WindowEnd:       cm.Tip().Height + 20,
+			RenterOutput:    types.SiacoinOutput{Address: types.StandardAddress(renterPubkey), Value: types.Siacoins(20)},
+			HostOutput:      types.SiacoinOutput{Address: types.StandardAddress(hostPubkey), Value: types.Siacoins(20)},
+			RenterPublicKey: renterPubkey,
+			HostPublicKey:   hostPubkey,
+		}
+		contractHash := cm.TipState().ContractSigHash(fc)
+		fc.RenterSignature = renterPrivkey.SignHash(contractHash)
+		fc.HostSignature = hostPrivkey.SignHash(contractHash)
+		tax := cm.TipState().FileContractTax(fc)
+
+		txn := types.Transaction{FileContracts: []types.FileContract{fc}}
+		toSign, _, err := w.FundTransaction(&txn, fc.RenterOutput.Value.Add(fc.HostOutput.Value).Add(tax), nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if err := w.SignTransaction(cm.TipState(), &txn, toSign); err != nil {
+			t.Fatal(err)
+		}
+		prevPool := cm.TipState().SiafundPool
+		if err := cm.AddTipBlock(sim.MineBlockWithTxns(txn)); err != nil {
+			t.Fatal(err)
+		}
+		history, err := e.SiafundPoolHistory(cm.Tip().Height, cm.Tip().Height)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(history) != 1 {
+			t.Fatal("missing pool history")
+		} else if history[0].Pool != cm.TipState().SiafundPool || history[0].Growth != tax || prevPool.Add(tax) != history[0].Pool {
+			t.Fatal("wrong pool history", history[0])
+		}
+	}
+	formContract()
+
+	// the genesis block's contracts fund the pool from nothing
+	if history, err := e.SiafundPoolHistory(0, 0); err != nil {
+		t.Fatal(err)
+	} else if len(history) != 1 || history[0].Pool != sim.Genesis.State.SiafundPool || history[0].Growth != history[0].Pool {
+		t.Fatal("wrong genesis pool history", history)
+	}
+
+	// the simulator spends all of its siafunds in every block
+	prev := cm.TipState()
+	b := sim.MineBlock()
+	if err := cm.AddTipBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	var holder types.Address
+	var exp types.Currency
+	var inputs int
+	for _, txn := range b.Transactions {
+		for i, in := range txn.SiafundInputs {
+			holder = in.Parent.Address
+			inputs++
+			// the recorded claim should match the output created by consensus
+			sc, err := e.SiafundClaim(in.Parent.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := e.SiacoinElement(txn.SiafundClaimOutputID(i))
+			if err != nil {
+				t.Fatal(err)
+			} else if sc.Claim != out.Value || sc.Claim.IsZero() {
+				t.Fatalf("expected claim %v, got %v", out.Value, sc.Claim)
+			} else if sc.PoolValue != prev.SiafundPool || sc.ClaimStart != in.Parent.ClaimStart || sc.Siafunds != in.Parent.Value {
+				t.Fatal("wrong claim", sc)
+			}
+			exp = exp.Add(sc.Claim)
+		}
+	}
+	if inputs == 0 {
+		t.Fatal("expected siafund inputs")
+	}
+	claims, _, err := e.SiafundClaims(holder, "", 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(claims) != inputs {
+		t.Fatalf("expected %v claims, got %v", inputs, len(claims))
+	}
+
+	// newly created siafund elements start accruing revenue once the pool
+	// grows again
+	if sr, err := e.SiafundRevenue(holder); err != nil {
+		t.Fatal(err)
+	} else if sr.Claimed != exp || !sr.Accrued.IsZero() {
+		t.Fatal("wrong revenue", sr)
+	}
+	formContract()
+	ids, _, err := e.UnspentSiafundElements(holder, "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accrued types.Currency
+	s := cm.TipState()
+	for _, id := range ids {
+		sfe, err := e.SiafundElement(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accrued = accrued.Add(s.SiafundPool.Sub(sfe.ClaimStart).Div64(s.SiafundCount()).Mul64(sfe.Value))
+	}
+	if sr, err := e.SiafundRevenue(holder); err != nil {
+		t.Fatal(err)
+	} else if sr.Claimed != exp || sr.Accrued != accrued || accrued.IsZero() {
+		t.Fatal("wrong revenue", sr)
+	}
+}
+
+func TestFoundation(t *testing.T) {
+	addrA, addrB := types.Address{1}, types.Address{2}
+	genesis := types.Block{
+		Header:       types.BlockHeader{Timestamp: time.Unix(734600000, 0)},
+		Transactions: []types.Transaction{{NewFoundationAddress: addrA}},
+	}
+	gau := consensus.GenesisUpdate(genesis, types.Work{NumHashes: [32]byte{31: 4}})
+
+	hs, err := explorerutil.NewHashStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := explorer.NewExplorer(gau.State, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{ApplyUpdate: gau, Block: genesis}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// consensus requires the current Foundation address to authorize a
+	// change, so apply the block directly
+	b := types.Block{
+		Header: types.BlockHeader{
+			Height:    1,
+			ParentID:  genesis.ID(),
+			Timestamp: genesis.Header.Timestamp.Add(time.Minute),
+		},
+		Transactions: []types.Transaction{{NewFoundationAddress: addrB}},
+	}
+	if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{ApplyUpdate: consensus.ApplyBlock(gau.State, b), Block: b}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for height, exp := range []types.Address{addrA, addrB, addrB} {
+		if addr, err := e.FoundationAddress(uint64(height)); err != nil {
+			t.Fatal(err)
+		} else if addr != exp {
+			t.Fatalf("expected Foundation address %v at height %v, got %v", exp, height, addr)
+		}
+	}
+	changes, _, err := e.FoundationChanges("", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []explorer.FoundationChange{
+		{Index: b.Index(), Transaction: b.Transactions[0].ID(), Previous: addrA, Address: addrB},
+		{Index: genesis.Index(), Transaction: genesis.Transactions[0].ID(), Previous: types.VoidAddress, Address: addrA},
+	}
+	if !reflect.DeepEqual(changes, exp) {
+		t.Fatalf("expected %v, got %v", exp, changes)
+	}
+
+	// reverting the block should restore the previous address
+	if err := e.ProcessChainRevertUpdate(&chain.RevertUpdate{RevertUpdate: consensus.RevertBlock(gau.State, b), Block: b}); err != nil {
+		t.Fatal(err)
+	} else if addr, err := e.FoundationAddress(1); err != nil {
+		t.Fatal(err)
+	} else if addr != addrA {
+		t.Fatal("expected Foundation address to be reverted, got", addr)
+	}
+
+	// skip ahead to the first block paying a subsidy
+	s := gau.State
+	s.Index.Height = 299999
+	e, err = explorer.NewExplorer(s, explorerutil.NewEphemeralStore(), hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = types.Block{
+		Header: types.BlockHeader{
+			Height:    s.Index.Height + 1,
+			ParentID:  s.Index.ID,
+			Timestamp: genesis.Header.Timestamp.Add(time.Minute),
+		},
+	}
+	if s.FoundationSubsidy().IsZero() {
+		t.Fatal("expected block to pay a subsidy")
+	} else if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{ApplyUpdate: consensus.ApplyBlock(s, b), Block: b}, true); err != nil {
+		t.Fatal(err)
+	}
+	subsidies, _, err := e.FoundationSubsidies("", 100)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(subsidies) != 1 {
+		t.Fatalf("expected 1 subsidy, got %v", len(subsidies))
+	}
+	fs := subsidies[0]
+	if fs.Index != b.Index() || fs.Address != addrA || fs.Value != s.FoundationSubsidy() || fs.Element != b.FoundationOutputID() {
+		t.Fatal("wrong subsidy", fs)
+	} else if sce, err := e.SiacoinElement(fs.Element); err != nil {
+		t.Fatal(err)
+	} else if sce.Address != fs.Address || sce.Value != fs.Value {
+		t.Fatal("subsidy does not match siacoin element", sce)
+	}
+}
+
+type stubPool struct {
+	mu   sync.Mutex
+	txns []types.Transaction
+}
+
+func (p *stubPool) Transactions() []types.Transaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]types.Transaction(nil), p.txns...)
+}
+
+func (p *stubPool) AddTransaction(txn types.Transaction) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.txns = append(p.txns, txn)
+	return nil
+}
+
+func (p *stubPool) setTransactions(txns ...types.Transaction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.txns = txns
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []explorer.Event
+}
+
+func (r *eventRecorder) ProcessEvent(ev explorer.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func TestMempool(t *testing.T) {
+	addrA, addrB := types.Address{1}, types.Address{2}
+	parent := types.Transaction{
+		SiacoinOutputs: []types.SiacoinOutput{{Address: addrA, Value: types.Siacoins(5)}},
+		MinerFee:       types.Siacoins(1),
+	}
+	child := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			Parent: types.SiacoinElement{
+				StateElement:  types.StateElement{ID: types.ElementID{Source: types.Hash256(parent.ID())}},
+				SiacoinOutput: parent.SiacoinOutputs[0],
+			},
+			SpendPolicy: types.AnyoneCanSpend(),
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{Address: addrB, Value: types.Siacoins(5)}},
+	}
+
+	tp := &stubPool{txns: []types.Transaction{parent}}
+	mp := explorer.NewMempool(tp)
+	defer mp.Close()
+	var rec eventRecorder
+	mp.AddEventSubscriber(&rec)
+
+	txns := mp.Transactions()
+	if len(txns) != 1 || txns[0].ID != parent.ID() || txns[0].Fee != parent.MinerFee || txns[0].Size != uint64(types.EncodedLen(parent)) {
+		t.Fatal("wrong transactions", txns)
+	} else if len(txns[0].Parents) != 0 || len(txns[0].Addresses) != 1 || txns[0].Addresses[0] != addrA {
+		t.Fatal("wrong transaction details", txns[0])
+	}
+	firstSeen := txns[0].FirstSeen
+
+	// transactions added through the mempool are visible immediately, and
+	// published
+	if err := mp.AddTransaction(child); err != nil {
+		t.Fatal(err)
+	}
+	txns = mp.Transactions()
+	if len(txns) != 2 || !txns[0].FirstSeen.Equal(firstSeen) {
+		t.Fatal("wrong transactions", txns)
+	} else if txns[1].ID != child.ID() || len(txns[1].Parents) != 1 || txns[1].Parents[0] != parent.ID() {
+		t.Fatal("wrong child transaction", txns[1])
+	} else if len(rec.events) != 1 || rec.events[0].Type != explorer.EventTransactionPooled || rec.events[0].Transaction != child.ID() {
+		t.Fatal("wrong pooled events", rec.events)
+	}
+
+	if pa := mp.PendingActivity(addrA); len(pa.Transactions) != 2 || pa.SiacoinsIn != types.Siacoins(5) || pa.SiacoinsOut != types.Siacoins(5) {
+		t.Fatal("wrong pending activity", pa)
+	} else if pa := mp.PendingActivity(addrB); len(pa.Transactions) != 1 || pa.SiacoinsIn != types.Siacoins(5) || !pa.SiacoinsOut.IsZero() {
+		t.Fatal("wrong pending activity", pa)
+	}
+
+	// confirming the parent removes it from the pool, which is published
+	tp.setTransactions(child)
+	if err := mp.ProcessChainApplyUpdate(nil, true); err != nil {
+		t.Fatal(err)
+	} else if txns := mp.Transactions(); len(txns) != 1 || txns[0].ID != child.ID() || len(txns[0].Parents) != 0 {
+		t.Fatal("wrong transactions", txns)
+	} else if pa := mp.PendingActivity(addrA); len(pa.Transactions) != 1 || !pa.SiacoinsIn.IsZero() {
+		t.Fatal("wrong pending activity", pa)
+	} else if len(rec.events) != 2 || rec.events[1].Type != explorer.EventTransactionRemoved || rec.events[1].Transaction != parent.ID() {
+		t.Fatal("wrong removal events", rec.events)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
-	"strings"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"go.sia.tech/core/consensus"
 	"go.sia.tech/core/types"
 	"go.sia.tech/explorer"
@@ -34,6 +39,106 @@ func scan(rows *sql.Rows, d types.DecoderFrom) error {
 	return decode(d, data)
 }
 
+// storeError translates database errors into their explorer.Store
+// equivalents.
+func storeError(err error) error {
+	var sqliteErr sqlite3.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return explorer.ErrNotFound
+	case errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked),
+		errors.Is(err, sql.ErrConnDone):
+		return fmt.Errorf("%w: %v", explorer.ErrUnavailable, err)
+	}
+	return err
+}
+
+// encodeCursor encodes the sort keys of the last item on a page as an opaque
+// cursor.
+func encodeCursor(keys ...uint64) explorer.Cursor {
+	buf := make([]byte, 8*len(keys))
+	for i, k := range keys {
+		binary.BigEndian.PutUint64(buf[8*i:], k)
+	}
+	return explorer.Cursor(base64.RawURLEncoding.EncodeToString(buf))
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor with n keys.
+func decodeCursor(cursor explorer.Cursor, n int) ([]uint64, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil || len(buf) != 8*n {
+		return nil, explorer.ErrInvalidCursor
+	}
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = binary.BigEndian.Uint64(buf[8*i:])
+		if keys[i] > math.MaxInt64 {
+			return nil, explorer.ErrInvalidCursor
+		}
+	}
+	return keys, nil
+}
+
+// encodeBalanceCursor encodes the last item on a rich list page as an opaque
+// cursor.
+func encodeBalanceCursor(rb explorer.RankedBalance) explorer.Cursor {
+	buf := make([]byte, 64)
+	binary.BigEndian.PutUint64(buf[:8], rb.Rank)
+	copy(buf[8:24], currencyOrder(rb.Siacoins))
+	binary.BigEndian.PutUint64(buf[24:32], rb.Siafunds)
+	copy(buf[32:], rb.Address[:])
+	return explorer.Cursor(base64.RawURLEncoding.EncodeToString(buf))
+}
+
+// decodeBalanceCursor decodes a cursor produced by encodeBalanceCursor.
+func decodeBalanceCursor(cursor explorer.Cursor) (rb explorer.RankedBalance, err error) {
+	buf, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil || len(buf) != 64 {
+		return explorer.RankedBalance{}, explorer.ErrInvalidCursor
+	}
+	rb.Rank = binary.BigEndian.Uint64(buf[:8])
+	rb.Siacoins = types.NewCurrency(binary.BigEndian.Uint64(buf[16:24]), binary.BigEndian.Uint64(buf[8:16]))
+	rb.Siafunds = binary.BigEndian.Uint64(buf[24:32])
+	copy(rb.Address[:], buf[32:])
+	if rb.Rank > math.MaxInt64 || rb.Siafunds > math.MaxInt64 {
+		return explorer.RankedBalance{}, explorer.ErrInvalidCursor
+	}
+	return rb, nil
+}
+
+// currencyOrder returns a fixed-width big-endian encoding of c, which sorts in
+// the same order as c.
+func currencyOrder(c types.Currency) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], c.Hi)
+	binary.BigEndian.PutUint64(buf[8:], c.Lo)
+	return buf
+}
+
+// storedBlock implements types.EncoderTo and types.DecoderFrom for a
+// types.Block.
+type storedBlock types.Block
+
+// EncodeTo implements types.EncoderTo.
+func (b storedBlock) EncodeTo(e *types.Encoder) {
+	b.Header.EncodeTo(e)
+	e.WritePrefix(len(b.Transactions))
+	for _, txn := range b.Transactions {
+		txn.EncodeTo(e)
+	}
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (b *storedBlock) DecodeFrom(d *types.Decoder) {
+	b.Header.DecodeFrom(d)
+	b.Transactions = make([]types.Transaction, d.ReadPrefix())
+	for i := range b.Transactions {
+		b.Transactions[i].DecodeFrom(d)
+	}
+}
+
 // SQLiteStore implements explorer.Store using a SQLite database.
 type SQLiteStore struct {
 	db    *sql.DB
@@ -50,22 +155,24 @@ func (s *SQLiteStore) beginTx() {
 func (s *SQLiteStore) query(query string, args ...interface{}) (*sql.Rows, error) {
 	s.beginTx()
 	if s.txErr != nil {
-		return nil, s.txErr
+		return nil, storeError(s.txErr)
 	}
-	return s.tx.Query(query, args...)
+	rows, err := s.tx.Query(query, args...)
+	return rows, storeError(err)
 }
 
 func (s *SQLiteStore) queryRow(d types.DecoderFrom, query string, args ...interface{}) error {
 	s.beginTx()
 	if s.txErr != nil {
-		return s.txErr
+		return storeError(s.txErr)
 	}
+	// NOTE: a failed lookup (e.g. sql.ErrNoRows) must not poison the pending
+	// transaction, so the error is returned rather than stored in txErr
 	var data []byte
-	s.txErr = s.tx.QueryRow(query, args...).Scan(&data)
-	if s.txErr == nil {
-		s.txErr = decode(d, data)
+	if err := s.tx.QueryRow(query, args...).Scan(&data); err != nil {
+		return storeError(err)
 	}
-	return s.txErr
+	return decode(d, data)
 }
 
 func (s *SQLiteStore) execStatement(statement string, args ...interface{}) {
@@ -120,34 +227,393 @@ func (s *SQLiteStore) FileContractElement(id types.ElementID) (fce types.FileCon
 	return
 }
 
+// ElementStatus implements explorer.Store.
+func (s *SQLiteStore) ElementStatus(id types.ElementID) (status explorer.ElementStatus, err error) {
+	s.beginTx()
+	if s.txErr != nil {
+		return explorer.ElementStatus{}, storeError(s.txErr)
+	}
+	var spentBy []byte
+	var spentHeight sql.NullInt64
+	if err := s.tx.QueryRow(`SELECT spentBy, spentHeight FROM elements WHERE id=?`, encode(id)).Scan(&spentBy, &spentHeight); err != nil {
+		return explorer.ElementStatus{}, storeError(err)
+	} else if spentBy == nil {
+		return explorer.ElementStatus{}, nil
+	}
+	status.Spent = true
+	status.SpentHeight = uint64(spentHeight.Int64)
+	err = decode(&status.SpentBy, spentBy)
+	return
+}
+
 // ChainStats implements explorer.Store.
 func (s *SQLiteStore) ChainStats(index types.ChainIndex) (cs explorer.ChainStats, err error) {
-	err = s.queryRow(&cs, `SELECT data FROM chainstats WHERE id=?`, index.String())
+	if err = s.queryRow(&cs, `SELECT data FROM chainstats WHERE id=?`, index.String()); err != nil {
+		return
+	}
+	// the block itself is stored once, in the blocks table
+	cs.Block, err = s.BlockByID(index.ID)
 	return
 }
 
+// BlockByHeight implements explorer.Store.
+func (s *SQLiteStore) BlockByHeight(height uint64) (types.Block, error) {
+	var b storedBlock
+	err := s.queryRow(&b, `SELECT data FROM blocks WHERE height=?`, height)
+	return types.Block(b), err
+}
+
+// BlockByID implements explorer.Store.
+func (s *SQLiteStore) BlockByID(id types.BlockID) (types.Block, error) {
+	var b storedBlock
+	err := s.queryRow(&b, `SELECT data FROM blocks WHERE id=?`, encode(id))
+	return types.Block(b), err
+}
+
+func (s *SQLiteStore) unspentElements(address types.Address, typ string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	after := uint64(math.MaxInt64)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		after = keys[0]
+	}
+	rows, err := s.query(`SELECT id, leafIndex FROM unspentElements WHERE address=? AND type=? AND leafIndex<? ORDER BY leafIndex DESC LIMIT ?`, encode(address), typ, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ids []types.ElementID
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var leafIndex uint64
+		if err := rows.Scan(&data, &leafIndex); err != nil {
+			return nil, "", err
+		} else if len(ids) == limit {
+			next = encodeCursor(after)
+			break
+		}
+		var id types.ElementID
+		if err := decode(&id, data); err != nil {
+			return nil, "", err
+		}
+		ids = append(ids, id)
+		after = leafIndex
+	}
+	return ids, next, rows.Err()
+}
+
 // UnspentSiacoinElements implements explorer.Store.
-func (s *SQLiteStore) UnspentSiacoinElements(address types.Address) ([]types.ElementID, error) {
-	rows, err := s.query(`SELECT id FROM unspentElements WHERE address=? AND type=?`, encode(address), "siacoin")
+func (s *SQLiteStore) UnspentSiacoinElements(address types.Address, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	return s.unspentElements(address, "siacoin", cursor, limit)
+}
+
+// UnspentSiafundElements implements explorer.Store.
+func (s *SQLiteStore) UnspentSiafundElements(address types.Address, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	return s.unspentElements(address, "siafund", cursor, limit)
+}
+
+// Transaction implements explorer.Store.
+func (s *SQLiteStore) Transaction(id types.TransactionID) (txn types.Transaction, err error) {
+	err = s.queryRow(&txn, `SELECT data FROM transactions WHERE id=?`, encode(id))
+	return
+}
+
+// TransactionMetadata implements explorer.Store.
+func (s *SQLiteStore) TransactionMetadata(id types.TransactionID) (meta explorer.TransactionMetadata, err error) {
+	s.beginTx()
+	if s.txErr != nil {
+		return explorer.TransactionMetadata{}, storeError(s.txErr)
+	}
+	var blockID []byte
+	var timestamp int64
+	err = s.tx.QueryRow(`SELECT height, block, position, timestamp FROM transactions WHERE id=?`, encode(id)).Scan(&meta.Block.Height, &blockID, &meta.Index, &timestamp)
+	if err != nil {
+		return explorer.TransactionMetadata{}, storeError(err)
+	}
+	meta.Timestamp = time.Unix(timestamp, 0).UTC()
+	err = decode(&meta.Block.ID, blockID)
+	return
+}
+
+// Transactions implements explorer.Store.
+func (s *SQLiteStore) Transactions(address types.Address, cursor explorer.Cursor, limit int) ([]types.TransactionID, explorer.Cursor, error) {
+	height, position := uint64(math.MaxInt64), uint64(0)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		height, position = keys[0], keys[1]
+	}
+	rows, err := s.query(`SELECT id, height, position FROM addressTransactions WHERE address=? AND (height<? OR (height=? AND position<?)) ORDER BY height DESC, position DESC LIMIT ?`, encode(address), height, height, position, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ids []types.TransactionID
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var h, p uint64
+		if err := rows.Scan(&data, &h, &p); err != nil {
+			return nil, "", err
+		} else if len(ids) == limit {
+			next = encodeCursor(height, position)
+			break
+		}
+		var id types.TransactionID
+		if err := decode(&id, data); err != nil {
+			return nil, "", err
+		}
+		ids = append(ids, id)
+		height, position = h, p
+	}
+	return ids, next, rows.Err()
+}
+
+// prefixUpperBound returns the smallest byte string greater than every string
+// beginning with prefix, or nil if there is none.
+func prefixUpperBound(prefix []byte) []byte {
+	upper := append([]byte(nil), prefix...)
+	for i := len(upper) - 1; i >= 0; i-- {
+		if upper[i] != 0xff {
+			upper[i]++
+			return upper[:i+1]
+		}
+	}
+	return nil
+}
+
+// ArbitraryDataTransactions implements explorer.Store.
+func (s *SQLiteStore) ArbitraryDataTransactions(prefix []byte, cursor explorer.Cursor, limit int) ([]types.TransactionID, explorer.Cursor, error) {
+	height, position := uint64(math.MaxInt64), uint64(0)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		height, position = keys[0], keys[1]
+	}
+	if prefix == nil {
+		prefix = []byte{}
+	}
+	upper := prefixUpperBound(prefix)
+	rows, err := s.query(`SELECT id, height, position FROM arbitraryData WHERE prefix>=? AND (? IS NULL OR prefix<?) AND (height<? OR (height=? AND position<?)) ORDER BY height DESC, position DESC LIMIT ?`, prefix, upper, upper, height, height, position, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ids []types.TransactionID
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var h, p uint64
+		if err := rows.Scan(&data, &h, &p); err != nil {
+			return nil, "", err
+		} else if len(ids) == limit {
+			next = encodeCursor(height, position)
+			break
+		}
+		var id types.TransactionID
+		if err := decode(&id, data); err != nil {
+			return nil, "", err
+		}
+		ids = append(ids, id)
+		height, position = h, p
+	}
+	return ids, next, rows.Err()
+}
+
+// BalanceHistory implements explorer.Store.
+func (s *SQLiteStore) BalanceHistory(address types.Address, start, end uint64) ([]explorer.HistoricalBalance, error) {
+	rows, err := s.query(`SELECT height, siacoins, siafunds FROM balanceDeltas WHERE address=? AND height>=? AND height<=? ORDER BY height ASC`, encode(address), start, end)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var history []explorer.HistoricalBalance
+	for rows.Next() {
+		var hb explorer.HistoricalBalance
+		var sc []byte
+		if err := rows.Scan(&hb.Height, &sc, &hb.Siafunds); err != nil {
+			return nil, err
+		} else if err := decode(&hb.Siacoins, sc); err != nil {
+			return nil, err
+		}
+		history = append(history, hb)
+	}
+	return history, rows.Err()
+}
+
+// AddressBalance implements explorer.Store.
+func (s *SQLiteStore) AddressBalance(address types.Address) (sc types.Currency, sf uint64, err error) {
+	s.beginTx()
+	if s.txErr != nil {
+		return types.Currency{}, 0, storeError(s.txErr)
+	}
+	var data []byte
+	err = s.tx.QueryRow(`SELECT siacoins, siafunds FROM addressBalances WHERE address=?`, encode(address)).Scan(&data, &sf)
+	if err == sql.ErrNoRows {
+		return types.ZeroCurrency, 0, nil
+	} else if err != nil {
+		return types.Currency{}, 0, storeError(err)
+	}
+	err = decode(&sc, data)
+	return
+}
+
+// richList returns a page of the addresses with a nonzero balance in the
+// order column, ordered by that column and then by address. key returns the
+// order column value of a balance; pages resume after the balance and address
+// of the last item on the previous page.
+func (s *SQLiteStore) richList(order string, zero interface{}, key func(explorer.RankedBalance) interface{}, cursor explorer.Cursor, limit int) ([]explorer.RankedBalance, explorer.Cursor, error) {
+	where, args := order+`>?`, []interface{}{zero}
+	var rank uint64
+	if cursor != "" {
+		last, err := decodeBalanceCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		rank = last.Rank
+		where += ` AND (` + order + `<? OR (` + order + `=? AND address>?))`
+		args = append(args, key(last), key(last), encode(last.Address))
+	}
+	rows, err := s.query(`SELECT address, siacoins, siafunds FROM addressBalances WHERE `+where+` ORDER BY `+order+` DESC, address ASC LIMIT ?`, append(args, limit+1)...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var balances []explorer.RankedBalance
+	var next explorer.Cursor
+	for rows.Next() {
+		var addr, sc []byte
+		rb := explorer.RankedBalance{Rank: rank + uint64(len(balances)) + 1}
+		if err := rows.Scan(&addr, &sc, &rb.Siafunds); err != nil {
+			return nil, "", err
+		} else if len(balances) == limit {
+			next = encodeBalanceCursor(balances[limit-1])
+			break
+		} else if err := decode(&rb.Address, addr); err != nil {
+			return nil, "", err
+		} else if err := decode(&rb.Siacoins, sc); err != nil {
+			return nil, "", err
+		}
+		balances = append(balances, rb)
+	}
+	return balances, next, rows.Err()
+}
+
+// SiacoinRichList implements explorer.Store.
+func (s *SQLiteStore) SiacoinRichList(cursor explorer.Cursor, limit int) ([]explorer.RankedBalance, explorer.Cursor, error) {
+	key := func(rb explorer.RankedBalance) interface{} { return currencyOrder(rb.Siacoins) }
+	return s.richList("siacoinsOrder", currencyOrder(types.ZeroCurrency), key, cursor, limit)
+}
+
+// SiafundRichList implements explorer.Store.
+func (s *SQLiteStore) SiafundRichList(cursor explorer.Cursor, limit int) ([]explorer.RankedBalance, explorer.Cursor, error) {
+	key := func(rb explorer.RankedBalance) interface{} { return rb.Siafunds }
+	return s.richList("siafunds", 0, key, cursor, limit)
+}
+
+// AddressRank implements explorer.Store.
+func (s *SQLiteStore) AddressRank(address types.Address) (scRank, sfRank uint64, err error) {
+	sc, sf, err := s.AddressBalance(address)
+	if err != nil {
+		return 0, 0, err
+	}
+	// an address's rank is its position in the order used by the rich list
+	count := func(query string, args ...interface{}) (n uint64) {
+		if err == nil {
+			err = storeError(s.tx.QueryRow(query, args...).Scan(&n))
+		}
+		return
+	}
+	order, addr := currencyOrder(sc), encode(address)
+	if !sc.IsZero() {
+		scRank = 1 + count(`SELECT COUNT(*) FROM addressBalances WHERE siacoinsOrder>? OR (siacoinsOrder=? AND address<?)`, order, order, addr)
+	}
+	if sf != 0 {
+		sfRank = 1 + count(`SELECT COUNT(*) FROM addressBalances WHERE siafunds>? OR (siafunds=? AND address<?)`, sf, sf, addr)
+	}
+	return
+}
+
+// ContractHistory implements explorer.Store.
+func (s *SQLiteStore) ContractHistory(id types.ElementID) ([]explorer.ContractEvent, error) {
+	rows, err := s.query(`SELECT data FROM contractEvents WHERE contract=? ORDER BY height ASC, rowid ASC`, encode(id))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []explorer.ContractEvent
+	for rows.Next() {
+		var ev explorer.ContractEvent
+		if err := scan(rows, &ev); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLiteStore) contracts(column string, pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	after := uint64(math.MaxInt64)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		after = keys[0]
+	}
+	rows, err := s.query(`SELECT id, leafIndex FROM contracts WHERE `+column+`=? AND (?='' OR status=?) AND leafIndex<? ORDER BY leafIndex DESC LIMIT ?`, encode(pubkey), status, status, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
 	var ids []types.ElementID
+	var next explorer.Cursor
 	for rows.Next() {
+		var data []byte
+		var leafIndex uint64
+		if err := rows.Scan(&data, &leafIndex); err != nil {
+			return nil, "", err
+		} else if len(ids) == limit {
+			next = encodeCursor(after)
+			break
+		}
 		var id types.ElementID
-		if err := scan(rows, &id); err != nil {
-			return nil, err
+		if err := decode(&id, data); err != nil {
+			return nil, "", err
 		}
 		ids = append(ids, id)
+		after = leafIndex
 	}
-	return ids, rows.Err()
+	return ids, next, rows.Err()
 }
 
-// UnspentSiafundElements implements explorer.Store.
-func (s *SQLiteStore) UnspentSiafundElements(address types.Address) ([]types.ElementID, error) {
-	rows, err := s.query(`SELECT id FROM unspentElements WHERE address=? AND type=?`, encode(address), "siafund")
+// HostContracts implements explorer.Store.
+func (s *SQLiteStore) HostContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	return s.contracts("host", pubkey, status, cursor, limit)
+}
+
+// RenterContracts implements explorer.Store.
+func (s *SQLiteStore) RenterContracts(pubkey types.PublicKey, status string, cursor explorer.Cursor, limit int) ([]types.ElementID, explorer.Cursor, error) {
+	return s.contracts("renter", pubkey, status, cursor, limit)
+}
+
+// ContractsExpiring implements explorer.Store.
+func (s *SQLiteStore) ContractsExpiring(from, to uint64) ([]types.ElementID, error) {
+	rows, err := s.query(`SELECT id FROM contracts WHERE status=? AND ((windowStart BETWEEN ? AND ?) OR (windowEnd BETWEEN ? AND ?)) ORDER BY windowStart ASC, leafIndex ASC`, explorer.ContractStatusActive, from, to, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -164,29 +630,321 @@ func (s *SQLiteStore) UnspentSiafundElements(address types.Address) ([]types.Ele
 	return ids, rows.Err()
 }
 
-// Transaction implements explorer.Store.
-func (s *SQLiteStore) Transaction(id types.TransactionID) (txn types.Transaction, err error) {
-	err = s.queryRow(&txn, `SELECT data FROM transactions WHERE id=?`, encode(id))
+// Attestations implements explorer.Store.
+func (s *SQLiteStore) Attestations(pubkey types.PublicKey, key string, cursor explorer.Cursor, limit int) ([]explorer.Attestation, explorer.Cursor, error) {
+	height, position := uint64(math.MaxInt64), uint64(0)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		height, position = keys[0], keys[1]
+	}
+	rows, err := s.query(`SELECT data, height, position FROM attestations WHERE pubkey=? AND (?='' OR key=?) AND (height<? OR (height=? AND position<?)) ORDER BY height DESC, position DESC LIMIT ?`, encode(pubkey), key, key, height, height, position, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var atts []explorer.Attestation
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var h, p uint64
+		if err := rows.Scan(&data, &h, &p); err != nil {
+			return nil, "", err
+		} else if len(atts) == limit {
+			next = encodeCursor(height, position)
+			break
+		}
+		var a explorer.Attestation
+		if err := decode(&a, data); err != nil {
+			return nil, "", err
+		}
+		atts = append(atts, a)
+		height, position = h, p
+	}
+	return atts, next, rows.Err()
+}
+
+// LatestAttestations implements explorer.Store.
+func (s *SQLiteStore) LatestAttestations(pubkey types.PublicKey) ([]explorer.Attestation, error) {
+	rows, err := s.query(`SELECT data FROM attestations a WHERE pubkey=? AND NOT EXISTS (
+	SELECT 1 FROM attestations b WHERE b.pubkey=a.pubkey AND b.key=a.key AND (b.height>a.height OR (b.height=a.height AND b.position>a.position))
+) ORDER BY key ASC`, encode(pubkey))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var atts []explorer.Attestation
+	for rows.Next() {
+		var a explorer.Attestation
+		if err := scan(rows, &a); err != nil {
+			return nil, err
+		}
+		atts = append(atts, a)
+	}
+	return atts, rows.Err()
+}
+
+// MinerPayout implements explorer.Store.
+func (s *SQLiteStore) MinerPayout(height uint64) (mp explorer.MinerPayout, err error) {
+	err = s.queryRow(&mp, `SELECT data FROM minerPayouts WHERE height=?`, height)
 	return
 }
 
-// Transactions implements explorer.Store.
-func (s *SQLiteStore) Transactions(address types.Address, amount, offset int) ([]types.TransactionID, error) {
-	rows, err := s.query(`SELECT id FROM addressTransactions WHERE address=? LIMIT ? OFFSET ?`, encode(address), amount, offset)
+// MinerBlocks implements explorer.Store.
+func (s *SQLiteStore) MinerBlocks(address types.Address, cursor explorer.Cursor, limit int) ([]explorer.MinerPayout, explorer.Cursor, error) {
+	after := uint64(math.MaxInt64)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		after = keys[0]
+	}
+	rows, err := s.query(`SELECT data FROM minerPayouts WHERE address=? AND height<? ORDER BY height DESC LIMIT ?`, encode(address), after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var payouts []explorer.MinerPayout
+	var next explorer.Cursor
+	for rows.Next() {
+		if len(payouts) == limit {
+			next = encodeCursor(after)
+			break
+		}
+		var mp explorer.MinerPayout
+		if err := scan(rows, &mp); err != nil {
+			return nil, "", err
+		}
+		payouts = append(payouts, mp)
+		after = mp.Index.Height
+	}
+	return payouts, next, rows.Err()
+}
+
+// MinerBlockCounts implements explorer.Store.
+func (s *SQLiteStore) MinerBlockCounts(start, end uint64) ([]explorer.MinerShare, error) {
+	rows, err := s.query(`SELECT address, COUNT(*) FROM minerPayouts WHERE height BETWEEN ? AND ? GROUP BY address ORDER BY COUNT(*) DESC, address ASC`, start, end)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var ids []types.TransactionID
+	var shares []explorer.MinerShare
 	for rows.Next() {
-		var id types.TransactionID
-		if err := scan(rows, &id); err != nil {
+		var ms explorer.MinerShare
+		var data []byte
+		if err := rows.Scan(&data, &ms.Blocks); err != nil {
+			return nil, err
+		} else if err := decode(&ms.Address, data); err != nil {
 			return nil, err
 		}
-		ids = append(ids, id)
+		shares = append(shares, ms)
 	}
-	return ids, rows.Err()
+	return shares, rows.Err()
+}
+
+// BlockMetrics implements explorer.Store.
+func (s *SQLiteStore) BlockMetrics(start, end uint64) ([]explorer.BlockMetrics, error) {
+	rows, err := s.query(`SELECT data FROM blockMetrics WHERE height BETWEEN ? AND ? ORDER BY height ASC`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var metrics []explorer.BlockMetrics
+	for rows.Next() {
+		var bm explorer.BlockMetrics
+		if err := scan(rows, &bm); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, bm)
+	}
+	return metrics, rows.Err()
+}
+
+// FeeStats implements explorer.Store.
+func (s *SQLiteStore) FeeStats(start, end uint64) ([]explorer.FeeStats, error) {
+	rows, err := s.query(`SELECT data FROM feeStats WHERE height BETWEEN ? AND ? ORDER BY height ASC`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []explorer.FeeStats
+	for rows.Next() {
+		var fs explorer.FeeStats
+		if err := scan(rows, &fs); err != nil {
+			return nil, err
+		}
+		stats = append(stats, fs)
+	}
+	return stats, rows.Err()
+}
+
+// SiafundClaim implements explorer.Store.
+func (s *SQLiteStore) SiafundClaim(id types.ElementID) (sc explorer.SiafundClaim, err error) {
+	err = s.queryRow(&sc, `SELECT data FROM siafundClaims WHERE element=?`, encode(id))
+	return
+}
+
+// SiafundClaims implements explorer.Store.
+func (s *SQLiteStore) SiafundClaims(address types.Address, cursor explorer.Cursor, limit int) ([]explorer.SiafundClaim, explorer.Cursor, error) {
+	height, position := uint64(math.MaxInt64), uint64(0)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		height, position = keys[0], keys[1]
+	}
+	rows, err := s.query(`SELECT data, height, position FROM siafundClaims WHERE address=? AND (height<? OR (height=? AND position<?)) ORDER BY height DESC, position DESC LIMIT ?`, encode(address), height, height, position, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var claims []explorer.SiafundClaim
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var h, p uint64
+		if err := rows.Scan(&data, &h, &p); err != nil {
+			return nil, "", err
+		} else if len(claims) == limit {
+			next = encodeCursor(height, position)
+			break
+		}
+		var sc explorer.SiafundClaim
+		if err := decode(&sc, data); err != nil {
+			return nil, "", err
+		}
+		claims = append(claims, sc)
+		height, position = h, p
+	}
+	return claims, next, rows.Err()
+}
+
+// ClaimedSiacoins implements explorer.Store.
+func (s *SQLiteStore) ClaimedSiacoins(address types.Address) (claimed types.Currency, err error) {
+	err = s.queryRow(&claimed, `SELECT claimed FROM siafundRevenue WHERE address=?`, encode(address))
+	if err == explorer.ErrNotFound {
+		return types.ZeroCurrency, nil
+	}
+	return
+}
+
+// SiafundPoolHistory implements explorer.Store.
+func (s *SQLiteStore) SiafundPoolHistory(start, end uint64) ([]explorer.SiafundPoolGrowth, error) {
+	rows, err := s.query(`SELECT data FROM siafundPool WHERE height BETWEEN ? AND ? ORDER BY height ASC`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []explorer.SiafundPoolGrowth
+	for rows.Next() {
+		var pg explorer.SiafundPoolGrowth
+		if err := scan(rows, &pg); err != nil {
+			return nil, err
+		}
+		history = append(history, pg)
+	}
+	return history, rows.Err()
+}
+
+// LatestFoundationChange implements explorer.Store.
+func (s *SQLiteStore) LatestFoundationChange(height uint64) (fc explorer.FoundationChange, err error) {
+	err = s.queryRow(&fc, `SELECT data FROM foundationChanges WHERE height<=? ORDER BY height DESC, position DESC LIMIT 1`, height)
+	return
+}
+
+// FoundationChanges implements explorer.Store.
+func (s *SQLiteStore) FoundationChanges(cursor explorer.Cursor, limit int) ([]explorer.FoundationChange, explorer.Cursor, error) {
+	height, position := uint64(math.MaxInt64), uint64(0)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		height, position = keys[0], keys[1]
+	}
+	rows, err := s.query(`SELECT data, height, position FROM foundationChanges WHERE height<? OR (height=? AND position<?) ORDER BY height DESC, position DESC LIMIT ?`, height, height, position, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var changes []explorer.FoundationChange
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var h, p uint64
+		if err := rows.Scan(&data, &h, &p); err != nil {
+			return nil, "", err
+		} else if len(changes) == limit {
+			next = encodeCursor(height, position)
+			break
+		}
+		var fc explorer.FoundationChange
+		if err := decode(&fc, data); err != nil {
+			return nil, "", err
+		}
+		changes = append(changes, fc)
+		height, position = h, p
+	}
+	return changes, next, rows.Err()
+}
+
+// FoundationSubsidies implements explorer.Store.
+func (s *SQLiteStore) FoundationSubsidies(cursor explorer.Cursor, limit int) ([]explorer.FoundationSubsidy, explorer.Cursor, error) {
+	after := uint64(math.MaxInt64)
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		after = keys[0]
+	}
+	rows, err := s.query(`SELECT data, height FROM foundationSubsidies WHERE height<? ORDER BY height DESC LIMIT ?`, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var subsidies []explorer.FoundationSubsidy
+	var next explorer.Cursor
+	for rows.Next() {
+		var data []byte
+		var height uint64
+		if err := rows.Scan(&data, &height); err != nil {
+			return nil, "", err
+		} else if len(subsidies) == limit {
+			next = encodeCursor(after)
+			break
+		}
+		var fs explorer.FoundationSubsidy
+		if err := decode(&fs, data); err != nil {
+			return nil, "", err
+		}
+		subsidies = append(subsidies, fs)
+		after = height
+	}
+	return subsidies, next, rows.Err()
+}
+
+// HostStats implements explorer.Store.
+func (s *SQLiteStore) HostStats(pubkey types.PublicKey) (hs explorer.HostStats, err error) {
+	err = s.queryRow(&hs, `SELECT data FROM hostStats WHERE pubkey=?`, encode(pubkey))
+	if errors.Is(err, explorer.ErrNotFound) {
+		return explorer.HostStats{}, nil
+	}
+	return
 }
 
 // State implements explorer.Store.
@@ -210,7 +968,7 @@ func (s *SQLiteStore) AddSiafundElement(sfe types.SiafundElement) {
 // AddFileContractElement implements explorer.Store.
 func (s *SQLiteStore) AddFileContractElement(fce types.FileContractElement) {
 	fce.MerkleProof = nil
-	s.execStatement(`INSERT INTO elements(id, type, data) VALUES(?, ?, ?)`, encode(fce.ID), "contract", encode(fce))
+	s.execStatement(`INSERT OR REPLACE INTO elements(id, type, data) VALUES(?, ?, ?)`, encode(fce.ID), "contract", encode(fce))
 }
 
 // RemoveElement implements explorer.Store.
@@ -218,19 +976,44 @@ func (s *SQLiteStore) RemoveElement(id types.ElementID) {
 	s.execStatement(`DELETE FROM elements WHERE id=?`, encode(id))
 }
 
+// SpendElement implements explorer.Store.
+func (s *SQLiteStore) SpendElement(id types.ElementID, txid types.TransactionID, height uint64) {
+	s.execStatement(`UPDATE elements SET spentBy=?, spentHeight=? WHERE id=?`, encode(txid), height, encode(id))
+}
+
+// UnspendElement implements explorer.Store.
+func (s *SQLiteStore) UnspendElement(id types.ElementID) {
+	s.execStatement(`UPDATE elements SET spentBy=NULL, spentHeight=NULL WHERE id=?`, encode(id))
+}
+
 // AddChainStats implements explorer.Store.
 func (s *SQLiteStore) AddChainStats(index types.ChainIndex, cs explorer.ChainStats) {
 	s.execStatement(`INSERT INTO chainstats(id, data) VALUES(?, ?)`, index.String(), encode(cs))
 }
 
+// RemoveChainStats implements explorer.Store.
+func (s *SQLiteStore) RemoveChainStats(index types.ChainIndex) {
+	s.execStatement(`DELETE FROM chainstats WHERE id=?`, index.String())
+}
+
+// AddBlock implements explorer.Store.
+func (s *SQLiteStore) AddBlock(b types.Block) {
+	s.execStatement(`INSERT INTO blocks(id, height, data) VALUES(?, ?, ?)`, encode(b.ID()), b.Header.Height, encode(storedBlock(b)))
+}
+
+// RemoveBlock implements explorer.Store.
+func (s *SQLiteStore) RemoveBlock(id types.BlockID) {
+	s.execStatement(`DELETE FROM blocks WHERE id=?`, encode(id))
+}
+
 // AddUnspentSiacoinElement implements explorer.Store.
-func (s *SQLiteStore) AddUnspentSiacoinElement(address types.Address, id types.ElementID) {
-	s.execStatement(`INSERT INTO unspentElements(address, type, id) VALUES(?, ?, ?)`, encode(address), "siacoin", encode(id))
+func (s *SQLiteStore) AddUnspentSiacoinElement(address types.Address, id types.ElementID, leafIndex uint64) {
+	s.execStatement(`INSERT INTO unspentElements(address, type, id, leafIndex) VALUES(?, ?, ?, ?)`, encode(address), "siacoin", encode(id), leafIndex)
 }
 
 // AddUnspentSiafundElement implements explorer.Store.
-func (s *SQLiteStore) AddUnspentSiafundElement(address types.Address, id types.ElementID) {
-	s.execStatement(`INSERT INTO unspentElements(address, type, id) VALUES(?, ?, ?)`, encode(address), "siafund", encode(id))
+func (s *SQLiteStore) AddUnspentSiafundElement(address types.Address, id types.ElementID, leafIndex uint64) {
+	s.execStatement(`INSERT INTO unspentElements(address, type, id, leafIndex) VALUES(?, ?, ?, ?)`, encode(address), "siafund", encode(id), leafIndex)
 }
 
 // RemoveUnspentSiacoinElement implements explorer.Store.
@@ -244,13 +1027,175 @@ func (s *SQLiteStore) RemoveUnspentSiafundElement(address types.Address, id type
 }
 
 // AddTransaction implements explorer.Store.
-func (s *SQLiteStore) AddTransaction(txn types.Transaction, addresses []types.Address, block types.ChainIndex) {
+func (s *SQLiteStore) AddTransaction(txn types.Transaction, addresses []types.Address, meta explorer.TransactionMetadata) {
 	id := encode(txn.ID())
-	s.execStatement(`INSERT INTO transactions(id, data) VALUES(?, ?)`, id, encode(txn))
+	s.execStatement(`INSERT INTO transactions(id, height, block, position, timestamp, data) VALUES(?, ?, ?, ?, ?, ?)`, id, meta.Block.Height, encode(meta.Block.ID), meta.Index, meta.Timestamp.Unix(), encode(txn))
 
 	for _, address := range addresses {
-		s.execStatement(`INSERT INTO addressTransactions(address, id) VALUES(?, ?)`, encode(address), id)
+		s.execStatement(`INSERT INTO addressTransactions(address, id, height, position) VALUES(?, ?, ?, ?)`, encode(address), id, meta.Block.Height, meta.Index)
+	}
+	if len(txn.ArbitraryData) != 0 {
+		prefix := txn.ArbitraryData
+		if len(prefix) > explorer.ArbitraryDataPrefixSize {
+			prefix = prefix[:explorer.ArbitraryDataPrefixSize]
+		}
+		s.execStatement(`INSERT INTO arbitraryData(id, height, position, prefix) VALUES(?, ?, ?, ?)`, id, meta.Block.Height, meta.Index, prefix)
+	}
+}
+
+// RemoveTransaction implements explorer.Store.
+func (s *SQLiteStore) RemoveTransaction(id types.TransactionID) {
+	s.execStatement(`DELETE FROM addressTransactions WHERE id=?`, encode(id))
+	s.execStatement(`DELETE FROM arbitraryData WHERE id=?`, encode(id))
+	s.execStatement(`DELETE FROM transactions WHERE id=?`, encode(id))
+}
+
+// AddBalanceDelta implements explorer.Store.
+func (s *SQLiteStore) AddBalanceDelta(address types.Address, d explorer.BalanceDelta, sc types.Currency, sf uint64) {
+	s.execStatement(`INSERT INTO balanceDeltas(address, height, siacoinsIn, siacoinsOut, siafundsIn, siafundsOut, siacoins, siafunds) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, encode(address), d.Height, encode(d.SiacoinsIn), encode(d.SiacoinsOut), d.SiafundsIn, d.SiafundsOut, encode(sc), sf)
+}
+
+// RemoveBalanceDeltas implements explorer.Store.
+func (s *SQLiteStore) RemoveBalanceDeltas(height uint64) {
+	s.execStatement(`DELETE FROM balanceDeltas WHERE height=?`, height)
+}
+
+// UpdateAddressBalance implements explorer.Store.
+func (s *SQLiteStore) UpdateAddressBalance(address types.Address, sc types.Currency, sf uint64) {
+	if sc.IsZero() && sf == 0 {
+		s.execStatement(`DELETE FROM addressBalances WHERE address=?`, encode(address))
+		return
 	}
+	s.execStatement(`INSERT OR REPLACE INTO addressBalances(address, siacoins, siacoinsOrder, siafunds) VALUES(?, ?, ?, ?)`, encode(address), encode(sc), currencyOrder(sc), sf)
+}
+
+// AddContractEvent implements explorer.Store.
+func (s *SQLiteStore) AddContractEvent(ev explorer.ContractEvent) {
+	s.execStatement(`INSERT INTO contractEvents(contract, height, data) VALUES(?, ?, ?)`, encode(ev.ContractID), ev.Index.Height, encode(ev))
+}
+
+// RemoveContractEvents implements explorer.Store.
+func (s *SQLiteStore) RemoveContractEvents(height uint64) {
+	s.execStatement(`DELETE FROM contractEvents WHERE height=?`, height)
+}
+
+// AddContract implements explorer.Store.
+func (s *SQLiteStore) AddContract(fce types.FileContractElement) {
+	fc := fce.FileContract
+	s.execStatement(`INSERT INTO contracts(id, renter, host, status, leafIndex, windowStart, windowEnd) VALUES(?, ?, ?, ?, ?, ?, ?)`, encode(fce.ID), encode(fc.RenterPublicKey), encode(fc.HostPublicKey), explorer.ContractStatusActive, fce.LeafIndex, fc.WindowStart, fc.WindowEnd)
+}
+
+// UpdateContractStatus implements explorer.Store.
+func (s *SQLiteStore) UpdateContractStatus(id types.ElementID, status string) {
+	s.execStatement(`UPDATE contracts SET status=? WHERE id=?`, status, encode(id))
+}
+
+// UpdateContractWindow implements explorer.Store.
+func (s *SQLiteStore) UpdateContractWindow(id types.ElementID, windowStart, windowEnd uint64) {
+	s.execStatement(`UPDATE contracts SET windowStart=?, windowEnd=? WHERE id=?`, windowStart, windowEnd, encode(id))
+}
+
+// RemoveContract implements explorer.Store.
+func (s *SQLiteStore) RemoveContract(id types.ElementID) {
+	s.execStatement(`DELETE FROM contracts WHERE id=?`, encode(id))
+}
+
+// UpdateHostStats implements explorer.Store.
+func (s *SQLiteStore) UpdateHostStats(pubkey types.PublicKey, hs explorer.HostStats) {
+	if hs == (explorer.HostStats{}) {
+		s.execStatement(`DELETE FROM hostStats WHERE pubkey=?`, encode(pubkey))
+		return
+	}
+	s.execStatement(`INSERT OR REPLACE INTO hostStats(pubkey, data) VALUES(?, ?)`, encode(pubkey), encode(hs))
+}
+
+// AddAttestation implements explorer.Store.
+func (s *SQLiteStore) AddAttestation(a explorer.Attestation, position int) {
+	s.execStatement(`INSERT INTO attestations(pubkey, key, height, position, data) VALUES(?, ?, ?, ?, ?)`, encode(a.Attestation.PublicKey), a.Attestation.Key, a.Index.Height, position, encode(a))
+}
+
+// RemoveAttestations implements explorer.Store.
+func (s *SQLiteStore) RemoveAttestations(height uint64) {
+	s.execStatement(`DELETE FROM attestations WHERE height=?`, height)
+}
+
+// AddMinerPayout implements explorer.Store.
+func (s *SQLiteStore) AddMinerPayout(mp explorer.MinerPayout) {
+	s.execStatement(`INSERT INTO minerPayouts(height, address, data) VALUES(?, ?, ?)`, mp.Index.Height, encode(mp.Address), encode(mp))
+}
+
+// RemoveMinerPayout implements explorer.Store.
+func (s *SQLiteStore) RemoveMinerPayout(height uint64) {
+	s.execStatement(`DELETE FROM minerPayouts WHERE height=?`, height)
+}
+
+// AddBlockMetrics implements explorer.Store.
+func (s *SQLiteStore) AddBlockMetrics(bm explorer.BlockMetrics) {
+	s.execStatement(`INSERT INTO blockMetrics(height, data) VALUES(?, ?)`, bm.Index.Height, encode(bm))
+}
+
+// RemoveBlockMetrics implements explorer.Store.
+func (s *SQLiteStore) RemoveBlockMetrics(height uint64) {
+	s.execStatement(`DELETE FROM blockMetrics WHERE height=?`, height)
+}
+
+// AddFeeStats implements explorer.Store.
+func (s *SQLiteStore) AddFeeStats(fs explorer.FeeStats) {
+	s.execStatement(`INSERT INTO feeStats(height, data) VALUES(?, ?)`, fs.Index.Height, encode(fs))
+}
+
+// RemoveFeeStats implements explorer.Store.
+func (s *SQLiteStore) RemoveFeeStats(height uint64) {
+	s.execStatement(`DELETE FROM feeStats WHERE height=?`, height)
+}
+
+// AddSiafundClaim implements explorer.Store.
+func (s *SQLiteStore) AddSiafundClaim(sc explorer.SiafundClaim, position int) {
+	s.execStatement(`INSERT INTO siafundClaims(element, address, height, position, data) VALUES(?, ?, ?, ?, ?)`, encode(sc.Element), encode(sc.Address), sc.Index.Height, position, encode(sc))
+}
+
+// RemoveSiafundClaims implements explorer.Store.
+func (s *SQLiteStore) RemoveSiafundClaims(height uint64) {
+	s.execStatement(`DELETE FROM siafundClaims WHERE height=?`, height)
+}
+
+// UpdateClaimedSiacoins implements explorer.Store.
+func (s *SQLiteStore) UpdateClaimedSiacoins(address types.Address, claimed types.Currency) {
+	if claimed.IsZero() {
+		s.execStatement(`DELETE FROM siafundRevenue WHERE address=?`, encode(address))
+		return
+	}
+	s.execStatement(`INSERT OR REPLACE INTO siafundRevenue(address, claimed) VALUES(?, ?)`, encode(address), encode(claimed))
+}
+
+// AddSiafundPoolGrowth implements explorer.Store.
+func (s *SQLiteStore) AddSiafundPoolGrowth(pg explorer.SiafundPoolGrowth) {
+	s.execStatement(`INSERT INTO siafundPool(height, data) VALUES(?, ?)`, pg.Index.Height, encode(pg))
+}
+
+// RemoveSiafundPoolGrowth implements explorer.Store.
+func (s *SQLiteStore) RemoveSiafundPoolGrowth(height uint64) {
+	s.execStatement(`DELETE FROM siafundPool WHERE height=?`, height)
+}
+
+// AddFoundationChange implements explorer.Store.
+func (s *SQLiteStore) AddFoundationChange(fc explorer.FoundationChange, position int) {
+	s.execStatement(`INSERT INTO foundationChanges(height, position, data) VALUES(?, ?, ?)`, fc.Index.Height, position, encode(fc))
+}
+
+// RemoveFoundationChanges implements explorer.Store.
+func (s *SQLiteStore) RemoveFoundationChanges(height uint64) {
+	s.execStatement(`DELETE FROM foundationChanges WHERE height=?`, height)
+}
+
+// AddFoundationSubsidy implements explorer.Store.
+func (s *SQLiteStore) AddFoundationSubsidy(fs explorer.FoundationSubsidy) {
+	s.execStatement(`INSERT INTO foundationSubsidies(height, data) VALUES(?, ?)`, fs.Index.Height, encode(fs))
+}
+
+// RemoveFoundationSubsidy implements explorer.Store.
+func (s *SQLiteStore) RemoveFoundationSubsidy(height uint64) {
+	s.execStatement(`DELETE FROM foundationSubsidies WHERE height=?`, height)
 }
 
 // AddState implements explorer.Store.
@@ -258,12 +1203,39 @@ func (s *SQLiteStore) AddState(index types.ChainIndex, context consensus.State)
 	s.execStatement(`INSERT INTO states(id, data) VALUES(?, ?)`, encode(index), encode(context))
 }
 
+// RemoveState implements explorer.Store.
+func (s *SQLiteStore) RemoveState(index types.ChainIndex) {
+	s.execStatement(`DELETE FROM states WHERE id=?`, encode(index))
+}
+
+// schemaVersion is the version of the schema created by createTables, recorded
+// in the database's user_version. It must be incremented whenever a table or
+// the encoding of a stored type changes.
+const schemaVersion = 2
+
+// createTables initializes an empty database. A database created with a
+// different schema version is rejected, as it has to be reindexed from
+// scratch.
 func createTables(db *sql.DB) error {
+	var version, tables int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	} else if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		return err
+	} else if tables != 0 {
+		if version != schemaVersion {
+			return fmt.Errorf("database has schema version %v, but version %v is required; delete it to reindex the chain", version, schemaVersion)
+		}
+		return nil
+	}
+
 	query := `
 CREATE TABLE elements (
 	id BINARY(128) PRIMARY KEY,
 	type BINARY(128),
-	data BLOB NOT NULL
+	data BLOB NOT NULL,
+	spentBy BINARY(128),
+	spentHeight INTEGER
 );
 
 CREATE TABLE states (
@@ -271,6 +1243,12 @@ CREATE TABLE states (
 	data BLOB NOT NULL
 );
 
+CREATE TABLE blocks (
+	id BINARY(128) PRIMARY KEY,
+	height INTEGER UNIQUE NOT NULL,
+	data BLOB NOT NULL
+);
+
 CREATE TABLE chainstats (
 	id BINARY(128) PRIMARY KEY,
 	data BLOB NOT NULL
@@ -279,23 +1257,156 @@ CREATE TABLE chainstats (
 CREATE TABLE unspentElements (
 	id BINARY(128) PRIMARY KEY,
 	type BINARY(128),
-	address BINARY(128)
+	address BINARY(128),
+	leafIndex INTEGER NOT NULL
 );
 
+CREATE INDEX unspentElementsAddress ON unspentElements(address, type, leafIndex DESC);
+
 CREATE TABLE transactions (
 	id BINARY(128) PRIMARY KEY,
+	height INTEGER NOT NULL,
+	block BINARY(128) NOT NULL,
+	position INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
 	data BLOB NOT NULL
 );
 
 CREATE TABLE addressTransactions (
 	id BINARY(128),
-	address BINARY(128)
+	address BINARY(128),
+	height INTEGER NOT NULL,
+	position INTEGER NOT NULL
+);
+
+CREATE INDEX addressTransactionsAddress ON addressTransactions(address, height DESC, position DESC);
+
+CREATE TABLE arbitraryData (
+	id BINARY(128) PRIMARY KEY,
+	height INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	prefix BLOB NOT NULL
+);
+
+CREATE INDEX arbitraryDataPrefix ON arbitraryData(prefix);
+
+CREATE TABLE balanceDeltas (
+	address BINARY(128) NOT NULL,
+	height INTEGER NOT NULL,
+	siacoinsIn BLOB NOT NULL,
+	siacoinsOut BLOB NOT NULL,
+	siafundsIn INTEGER NOT NULL,
+	siafundsOut INTEGER NOT NULL,
+	siacoins BLOB NOT NULL,
+	siafunds INTEGER NOT NULL,
+	PRIMARY KEY(address, height)
+);
+
+CREATE INDEX balanceDeltasHeight ON balanceDeltas(height);
+
+CREATE TABLE addressBalances (
+	address BINARY(128) PRIMARY KEY,
+	siacoins BLOB NOT NULL,
+	siacoinsOrder BINARY(16) NOT NULL,
+	siafunds INTEGER NOT NULL
+);
+
+CREATE INDEX addressBalancesSiacoins ON addressBalances(siacoinsOrder DESC, address);
+CREATE INDEX addressBalancesSiafunds ON addressBalances(siafunds DESC, address);
+
+CREATE TABLE contractEvents (
+	contract BINARY(128) NOT NULL,
+	height INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE INDEX contractEventsContract ON contractEvents(contract, height);
+CREATE INDEX contractEventsHeight ON contractEvents(height);
+
+CREATE TABLE contracts (
+	id BINARY(128) PRIMARY KEY,
+	renter BINARY(128) NOT NULL,
+	host BINARY(128) NOT NULL,
+	status TEXT NOT NULL,
+	leafIndex INTEGER NOT NULL,
+	windowStart INTEGER NOT NULL,
+	windowEnd INTEGER NOT NULL
+);
+
+CREATE INDEX contractsRenter ON contracts(renter, leafIndex DESC);
+CREATE INDEX contractsHost ON contracts(host, leafIndex DESC);
+CREATE INDEX contractsWindowStart ON contracts(windowStart);
+CREATE INDEX contractsWindowEnd ON contracts(windowEnd);
+
+CREATE TABLE attestations (
+	pubkey BINARY(128) NOT NULL,
+	key TEXT NOT NULL,
+	height INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE INDEX attestationsPubkey ON attestations(pubkey, key, height DESC, position DESC);
+CREATE INDEX attestationsHeight ON attestations(height);
+
+CREATE TABLE minerPayouts (
+	height INTEGER PRIMARY KEY,
+	address BINARY(128) NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE INDEX minerPayoutsAddress ON minerPayouts(address, height DESC);
+
+CREATE TABLE blockMetrics (
+	height INTEGER PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE feeStats (
+	height INTEGER PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE siafundClaims (
+	element BINARY(128) PRIMARY KEY,
+	address BINARY(128) NOT NULL,
+	height INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE INDEX siafundClaimsAddress ON siafundClaims(address, height DESC, position DESC);
+CREATE INDEX siafundClaimsHeight ON siafundClaims(height);
+
+CREATE TABLE siafundRevenue (
+	address BINARY(128) PRIMARY KEY,
+	claimed BLOB NOT NULL
+);
+
+CREATE TABLE siafundPool (
+	height INTEGER PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE foundationChanges (
+	height INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE INDEX foundationChangesHeight ON foundationChanges(height DESC, position DESC);
+
+CREATE TABLE foundationSubsidies (
+	height INTEGER PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE hostStats (
+	pubkey BINARY(128) PRIMARY KEY,
+	data BLOB NOT NULL
 );
 `
-	_, err := db.Exec(query)
-	if err != nil && strings.Contains(err.Error(), "already exists") {
-		err = nil
-	}
+	_, err := db.Exec(query + fmt.Sprintf("PRAGMA user_version = %d;", schemaVersion))
 	return err
 }
 
@@ -0,0 +1,241 @@
+package explorerutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.sia.tech/core/chain"
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+	"go.sia.tech/explorer"
+	"go.sia.tech/explorer/internal/chainutil"
+)
+
+func newTestExplorer(tb testing.TB, genesis consensus.Checkpoint) (*explorer.Explorer, *SQLiteStore) {
+	hs, err := NewHashStore(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	store := NewEphemeralStore()
+	e, err := explorer.NewExplorer(genesis.State, store, hs)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := e.ProcessChainApplyUpdate(&chain.ApplyUpdate{
+		ApplyUpdate: consensus.GenesisUpdate(genesis.Block, types.Work{NumHashes: [32]byte{31: 4}}),
+		Block:       genesis.Block,
+	}, true); err != nil {
+		tb.Fatal(err)
+	}
+	return e, store
+}
+
+// dumpTables returns the sorted contents of every table in the store.
+func dumpTables(tb testing.TB, s *SQLiteStore) map[string][]string {
+	rows, err := s.query(`SELECT name FROM sqlite_master WHERE type='table'`)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			tb.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	dump := make(map[string][]string)
+	for _, table := range tables {
+		rows, err := s.query(`SELECT * FROM ` + table)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		cols, err := rows.Columns()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		for rows.Next() {
+			vals := make([]interface{}, len(cols))
+			ptrs := make([]interface{}, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				tb.Fatal(err)
+			}
+			dump[table] = append(dump[table], fmt.Sprintf("%x", vals))
+		}
+		rows.Close()
+		sort.Strings(dump[table])
+	}
+	return dump
+}
+
+type eventRecorder []explorer.Event
+
+func (r *eventRecorder) ProcessEvent(ev explorer.Event) { *r = append(*r, ev) }
+
+func TestReorg(t *testing.T) {
+	sim := chainutil.NewChainSim()
+	cm := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.State)
+	e, store := newTestExplorer(t, sim.Genesis)
+	cm.AddSubscriber(e, cm.Tip())
+
+	// mine 5 blocks, fork, then mine 5 more blocks
+	sim.MineBlocks(5)
+	fork := sim.Fork()
+	sim.MineBlocks(5)
+	for _, b := range sim.Chain {
+		if err := cm.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var events eventRecorder
+	e.AddEventSubscriber(&events)
+
+	// mine 10 blocks on the fork, ensuring that it has more total work, and
+	// give them to the manager
+	betterChain := fork.MineBlocks(10)
+	chainutil.FindBlockNonce(&betterChain[9].Header, types.HashRequiringWork(sim.State.TotalWork))
+	if _, err := cm.AddHeaders(chainutil.JustHeaders(betterChain)); err != nil {
+		t.Fatal(err)
+	} else if _, err := cm.AddBlocks(betterChain); err != nil {
+		t.Fatal(err)
+	} else if cm.Tip() != betterChain[len(betterChain)-1].Index() {
+		t.Fatal("didn't reorg to better chain")
+	}
+
+	// the orphaned blocks should be reverted, newest first, before the better
+	// chain is applied
+	var reverted, applied []types.ChainIndex
+	for _, ev := range events {
+		switch ev.Type {
+		case explorer.EventBlockReverted:
+			reverted = append(reverted, ev.Index)
+		case explorer.EventBlockApplied:
+			applied = append(applied, ev.Index)
+		}
+	}
+	if len(reverted) != 5 || reverted[0] != sim.Chain[9].Index() || reverted[4] != sim.Chain[5].Index() {
+		t.Fatal("wrong reverted blocks", reverted)
+	} else if len(applied) != 10 || applied[9] != cm.Tip() {
+		t.Fatal("wrong applied blocks", applied)
+	}
+
+	// build a second explorer that only ever sees the better chain
+	cm2 := chain.NewManager(chainutil.NewEphemeralStore(sim.Genesis), sim.Genesis.State)
+	e2, store2 := newTestExplorer(t, sim.Genesis)
+	cm2.AddSubscriber(e2, cm2.Tip())
+	for _, b := range append(fork.Chain[:5:5], betterChain...) {
+		if err := cm2.AddTipBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dump, dump2 := dumpTables(t, store), dumpTables(t, store2)
+	for table := range dump2 {
+		if !reflect.DeepEqual(dump[table], dump2[table]) {
+			t.Errorf("table %v does not match after reorg (%v rows, expected %v)", table, len(dump[table]), len(dump2[table]))
+		}
+	}
+	for table := range dump {
+		if _, ok := dump2[table]; !ok && len(dump[table]) != 0 {
+			t.Errorf("table %v should be empty after reorg", table)
+		}
+	}
+
+	// transactions from the orphaned blocks should be gone
+	for _, b := range sim.Chain[5:] {
+		for _, txn := range b.Transactions {
+			if _, err := e.Transaction(txn.ID()); err == nil {
+				t.Fatal("orphaned transaction should have been removed")
+			}
+		}
+	}
+}
+
+func TestWebhookStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.db")
+	s, err := NewWebhookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := []explorer.Webhook{
+		{URL: "http://a", Secret: "foo", Addresses: []types.Address{{1}, {2}}},
+		{URL: "http://b", Secret: "bar", Contracts: []types.ElementID{{Index: 3}}},
+	}
+	for i := range hooks {
+		if hooks[i].ID, err = s.AddWebhook(hooks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deliveries := []explorer.WebhookDelivery{
+		{Webhook: hooks[0].ID, Body: []byte("foo")},
+		{Webhook: hooks[1].ID, Body: []byte("bar")},
+		{Webhook: hooks[1].ID, Body: []byte("baz")},
+	}
+	for i := range deliveries {
+		if deliveries[i].ID, err = s.AddDelivery(deliveries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deliveries[1].Attempts = 3
+	if err := s.UpdateDelivery(deliveries[1].ID, 3); err != nil {
+		t.Fatal(err)
+	} else if err := s.RemoveDelivery(deliveries[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveWebhook(hooks[0].ID); err != nil {
+		t.Fatal(err)
+	} else if err := s.RemoveWebhook(hooks[0].ID); err != explorer.ErrNotFound {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+	s.Close()
+
+	// webhooks should persist across restarts
+	s, err = NewWebhookStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, err := s.Webhooks(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(got, hooks[1:]) {
+		t.Fatal("wrong webhooks", got)
+	}
+	// as should their pending deliveries
+	if got, err := s.Deliveries(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(got, deliveries[1:2]) {
+		t.Fatal("wrong deliveries", got)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.db.Close()
+
+	// reopening a current database should succeed
+	s, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a database from an older version should be rejected
+	if _, err := s.db.Exec(`PRAGMA user_version = 0`); err != nil {
+		t.Fatal(err)
+	}
+	s.db.Close()
+	if _, err := NewStore(path); err == nil {
+		t.Fatal("expected old database to be rejected")
+	}
+}
@@ -0,0 +1,215 @@
+package explorerutil
+
+import (
+	"database/sql"
+
+	"go.sia.tech/core/types"
+	"go.sia.tech/explorer"
+)
+
+// WebhookStore implements explorer.WebhookStore using a SQLite database.
+type WebhookStore struct {
+	db *sql.DB
+}
+
+// AddWebhook implements explorer.WebhookStore.
+func (s *WebhookStore) AddWebhook(wh explorer.Webhook) (id int64, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	res, err := tx.Exec(`INSERT INTO webhooks(url, secret) VALUES(?, ?)`, wh.URL, wh.Secret)
+	if err != nil {
+		return 0, err
+	} else if id, err = res.LastInsertId(); err != nil {
+		return 0, err
+	}
+	for _, addr := range wh.Addresses {
+		if _, err = tx.Exec(`INSERT INTO webhookWatches(webhook, type, id) VALUES(?, ?, ?)`, id, "address", encode(addr)); err != nil {
+			return 0, err
+		}
+	}
+	for _, fcid := range wh.Contracts {
+		if _, err = tx.Exec(`INSERT INTO webhookWatches(webhook, type, id) VALUES(?, ?, ?)`, id, "contract", encode(fcid)); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// RemoveWebhook implements explorer.WebhookStore.
+func (s *WebhookStore) RemoveWebhook(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM webhookWatches WHERE webhook=?`, id); err != nil {
+		tx.Rollback()
+		return err
+	} else if _, err := tx.Exec(`DELETE FROM webhookDeliveries WHERE webhook=?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM webhooks WHERE id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	} else if n, err := res.RowsAffected(); err != nil {
+		tx.Rollback()
+		return err
+	} else if n == 0 {
+		tx.Rollback()
+		return explorer.ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// Webhooks implements explorer.WebhookStore.
+func (s *WebhookStore) Webhooks() ([]explorer.Webhook, error) {
+	rows, err := s.db.Query(`SELECT id, url, secret FROM webhooks ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []explorer.Webhook
+	index := make(map[int64]int)
+	for rows.Next() {
+		var wh explorer.Webhook
+		if err := rows.Scan(&wh.ID, &wh.URL, &wh.Secret); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[wh.ID] = len(hooks)
+		hooks = append(hooks, wh)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT webhook, type, id FROM webhookWatches`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var webhook int64
+		var typ string
+		var data []byte
+		if err := rows.Scan(&webhook, &typ, &data); err != nil {
+			return nil, err
+		}
+		wh := &hooks[index[webhook]]
+		switch typ {
+		case "address":
+			var addr types.Address
+			if err := decode(&addr, data); err != nil {
+				return nil, err
+			}
+			wh.Addresses = append(wh.Addresses, addr)
+		case "contract":
+			var fcid types.ElementID
+			if err := decode(&fcid, data); err != nil {
+				return nil, err
+			}
+			wh.Contracts = append(wh.Contracts, fcid)
+		}
+	}
+	return hooks, rows.Err()
+}
+
+// AddDelivery implements explorer.WebhookStore.
+func (s *WebhookStore) AddDelivery(d explorer.WebhookDelivery) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO webhookDeliveries(webhook, body, attempts) VALUES(?, ?, ?)`, d.Webhook, d.Body, d.Attempts)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// UpdateDelivery implements explorer.WebhookStore.
+func (s *WebhookStore) UpdateDelivery(id int64, attempts int) error {
+	_, err := s.db.Exec(`UPDATE webhookDeliveries SET attempts=? WHERE id=?`, attempts, id)
+	return err
+}
+
+// RemoveDelivery implements explorer.WebhookStore.
+func (s *WebhookStore) RemoveDelivery(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM webhookDeliveries WHERE id=?`, id)
+	return err
+}
+
+// Deliveries implements explorer.WebhookStore.
+func (s *WebhookStore) Deliveries() ([]explorer.WebhookDelivery, error) {
+	rows, err := s.db.Query(`SELECT id, webhook, body, attempts FROM webhookDeliveries ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deliveries []explorer.WebhookDelivery
+	for rows.Next() {
+		var d explorer.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.Webhook, &d.Body, &d.Attempts); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func createWebhookTables(db *sql.DB) error {
+	_, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS webhooks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL,
+	secret TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhookWatches (
+	webhook INTEGER NOT NULL REFERENCES webhooks(id),
+	type TEXT NOT NULL,
+	id BINARY(128) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhookDeliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook INTEGER NOT NULL REFERENCES webhooks(id),
+	body BLOB NOT NULL,
+	attempts INTEGER NOT NULL
+);
+`)
+	return err
+}
+
+// NewWebhookStore creates a new WebhookStore for storing webhooks.
+func NewWebhookStore(path string) (*WebhookStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// an in-memory database is private to its connection
+	db.SetMaxOpenConns(1)
+	if err := createWebhookTables(db); err != nil {
+		return nil, err
+	}
+	return &WebhookStore{db: db}, nil
+}
+
+// NewEphemeralWebhookStore returns a new in-memory WebhookStore.
+func NewEphemeralWebhookStore() *WebhookStore {
+	s, err := NewWebhookStore(":memory:")
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Close closes the underlying database.
+func (s *WebhookStore) Close() error {
+	return s.db.Close()
+}
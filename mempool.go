@@ -0,0 +1,249 @@
+package explorer
+
+import (
+	"sync"
+	"time"
+
+	"go.sia.tech/core/chain"
+	"go.sia.tech/core/types"
+)
+
+// A TransactionPool contains unconfirmed transactions.
+type TransactionPool interface {
+	Transactions() []types.Transaction
+	AddTransaction(txn types.Transaction) error
+}
+
+// An UnconfirmedTransaction is a transaction in the transaction pool. Parents
+// lists the pooled transactions that create elements it spends; they must be
+// confirmed before or alongside it.
+type UnconfirmedTransaction struct {
+	ID          types.TransactionID   `json:"id"`
+	Transaction types.Transaction     `json:"transaction"`
+	Fee         types.Currency        `json:"fee"`
+	Size        uint64                `json:"size"`
+	FirstSeen   time.Time             `json:"firstSeen"`
+	Addresses   []types.Address       `json:"addresses"`
+	Parents     []types.TransactionID `json:"parents"`
+}
+
+// PendingActivity summarizes the unconfirmed transactions involving an
+// address, and the value they would send to and from it.
+type PendingActivity struct {
+	Transactions []UnconfirmedTransaction `json:"transactions"`
+	SiacoinsIn   types.Currency           `json:"siacoinsIn"`
+	SiacoinsOut  types.Currency           `json:"siacoinsOut"`
+	SiafundsIn   uint64                   `json:"siafundsIn"`
+	SiafundsOut  uint64                   `json:"siafundsOut"`
+}
+
+// mempoolPollInterval is the interval at which a Mempool checks its pool for
+// transactions added by other sources, such as peers.
+const mempoolPollInterval = time.Second
+
+// A Mempool observes a transaction pool, tracking when each transaction was
+// added to it, and publishing an EventTransactionPooled for each addition and
+// an EventTransactionRemoved for each removal. It is the single view of the pool
+// used by the API: transactions should be added through it, and it should be
+// subscribed to the chain manager after the pool, so that it sees the
+// transactions each block removes.
+type Mempool struct {
+	tp TransactionPool
+
+	// syncMu serializes syncs, so that their events are published in order
+	syncMu sync.Mutex
+
+	mu          sync.Mutex
+	txns        []types.Transaction
+	firstSeen   map[types.TransactionID]time.Time
+	subscribers map[EventSubscriber]struct{}
+	closed      chan struct{}
+}
+
+// sync records the transactions added to and removed from the pool since the
+// last sync, and publishes an event for each.
+func (m *Mempool) sync() {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	txns := m.tp.Transactions()
+	now := time.Now()
+
+	m.mu.Lock()
+	pooled := make(map[types.TransactionID]bool, len(txns))
+	var events []Event
+	for _, txn := range m.txns {
+		pooled[txn.ID()] = true
+	}
+	for _, txn := range txns {
+		txid := txn.ID()
+		if pooled[txid] {
+			delete(pooled, txid)
+		} else {
+			m.firstSeen[txid] = now
+			events = append(events, TransactionEvent(EventTransactionPooled, types.ChainIndex{}, txn))
+		}
+	}
+	// any transaction left in pooled is no longer in the pool
+	for _, txn := range m.txns {
+		if txid := txn.ID(); pooled[txid] {
+			delete(m.firstSeen, txid)
+			events = append(events, TransactionEvent(EventTransactionRemoved, types.ChainIndex{}, txn))
+		}
+	}
+	m.txns = txns
+	subscribers := make([]EventSubscriber, 0, len(m.subscribers))
+	for s := range m.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	m.mu.Unlock()
+
+	for _, s := range subscribers {
+		for _, ev := range events {
+			s.ProcessEvent(ev)
+		}
+	}
+}
+
+func (m *Mempool) run() {
+	ticker := time.NewTicker(mempoolPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.sync()
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// ProcessChainApplyUpdate implements chain.Subscriber.
+func (m *Mempool) ProcessChainApplyUpdate(*chain.ApplyUpdate, bool) error {
+	m.sync()
+	return nil
+}
+
+// ProcessChainRevertUpdate implements chain.Subscriber.
+func (m *Mempool) ProcessChainRevertUpdate(*chain.RevertUpdate) error {
+	m.sync()
+	return nil
+}
+
+// AddTransaction adds a transaction to the pool.
+func (m *Mempool) AddTransaction(txn types.Transaction) error {
+	if err := m.tp.AddTransaction(txn); err != nil {
+		return err
+	}
+	m.sync()
+	return nil
+}
+
+// Transactions returns the transactions currently in the pool.
+func (m *Mempool) Transactions() []UnconfirmedTransaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	creators := make(map[types.ElementID]types.TransactionID)
+	for _, txn := range m.txns {
+		txid := txn.ID()
+		for _, id := range transactionOutputs(txn) {
+			creators[id] = txid
+		}
+	}
+
+	uts := make([]UnconfirmedTransaction, 0, len(m.txns))
+	for _, txn := range m.txns {
+		ut := UnconfirmedTransaction{
+			ID:          txn.ID(),
+			Transaction: txn,
+			Fee:         txn.MinerFee,
+			Size:        uint64(types.EncodedLen(txn)),
+			FirstSeen:   m.firstSeen[txn.ID()],
+			Addresses:   transactionAddresses(txn),
+		}
+		seen := make(map[types.TransactionID]bool)
+		for _, id := range transactionInputs(txn) {
+			if parent, ok := creators[id]; ok && !seen[parent] {
+				seen[parent] = true
+				ut.Parents = append(ut.Parents, parent)
+			}
+		}
+		uts = append(uts, ut)
+	}
+	return uts
+}
+
+// PendingActivity returns the unconfirmed transactions involving the
+// specified address.
+func (m *Mempool) PendingActivity(address types.Address) PendingActivity {
+	pa := PendingActivity{Transactions: []UnconfirmedTransaction{}}
+	for _, ut := range m.Transactions() {
+		involved := false
+		for _, addr := range ut.Addresses {
+			if addr == address {
+				involved = true
+				break
+			}
+		}
+		if !involved {
+			continue
+		}
+		pa.Transactions = append(pa.Transactions, ut)
+		txn := ut.Transaction
+		for _, in := range txn.SiacoinInputs {
+			if in.Parent.Address == address {
+				pa.SiacoinsOut = pa.SiacoinsOut.Add(in.Parent.Value)
+			}
+		}
+		for _, out := range txn.SiacoinOutputs {
+			if out.Address == address {
+				pa.SiacoinsIn = pa.SiacoinsIn.Add(out.Value)
+			}
+		}
+		for _, in := range txn.SiafundInputs {
+			if in.Parent.Address == address {
+				pa.SiafundsOut += in.Parent.Value
+			}
+		}
+		for _, out := range txn.SiafundOutputs {
+			if out.Address == address {
+				pa.SiafundsIn += out.Value
+			}
+		}
+	}
+	return pa
+}
+
+// AddEventSubscriber subscribes s to the EventTransactionPooled and
+// EventTransactionRemoved events published by the mempool.
+func (m *Mempool) AddEventSubscriber(s EventSubscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers[s] = struct{}{}
+}
+
+// RemoveEventSubscriber unsubscribes s from the events published by the
+// mempool.
+func (m *Mempool) RemoveEventSubscriber(s EventSubscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subscribers, s)
+}
+
+// Close stops observing the pool.
+func (m *Mempool) Close() error {
+	close(m.closed)
+	return nil
+}
+
+// NewMempool returns a Mempool observing tp.
+func NewMempool(tp TransactionPool) *Mempool {
+	m := &Mempool{
+		tp:          tp,
+		firstSeen:   make(map[types.TransactionID]time.Time),
+		subscribers: make(map[EventSubscriber]struct{}),
+		closed:      make(chan struct{}),
+	}
+	m.sync()
+	go m.run()
+	return m
+}
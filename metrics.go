@@ -0,0 +1,83 @@
+package explorer
+
+import (
+	"time"
+
+	"go.sia.tech/core/types"
+)
+
+// BlockMetrics contains the network metrics derived from the consensus state
+// after a block was applied.
+type BlockMetrics struct {
+	Index      types.ChainIndex
+	Timestamp  time.Time
+	Difficulty types.Work
+	TotalWork  types.Work
+}
+
+// EncodeTo implements types.EncoderTo.
+func (bm BlockMetrics) EncodeTo(e *types.Encoder) {
+	bm.Index.EncodeTo(e)
+	e.WriteTime(bm.Timestamp)
+	bm.Difficulty.EncodeTo(e)
+	bm.TotalWork.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (bm *BlockMetrics) DecodeFrom(d *types.Decoder) {
+	bm.Index.DecodeFrom(d)
+	bm.Timestamp = d.ReadTime()
+	bm.Difficulty.DecodeFrom(d)
+	bm.TotalWork.DecodeFrom(d)
+}
+
+// NetworkMetrics summarizes the network over a range of blocks. Difficulty and
+// TotalWork are those at the end of the range; Hashrate is the estimated
+// number of hashes per second performed over the range.
+type NetworkMetrics struct {
+	Start            types.ChainIndex
+	End              types.ChainIndex
+	Difficulty       types.Work
+	TotalWork        types.Work
+	Hashrate         types.Work
+	AverageBlockTime time.Duration
+}
+
+// BlockMetrics returns the network metrics of each block in the best chain at
+// heights in [start, end].
+func (e *Explorer) BlockMetrics(start, end uint64) ([]BlockMetrics, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.BlockMetrics(start, end)
+}
+
+// NetworkMetrics returns the network metrics over the blocks in the best chain
+// at heights in [start, end].
+func (e *Explorer) NetworkMetrics(start, end uint64) (NetworkMetrics, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	first, err := e.db.BlockMetrics(start, start)
+	if err != nil {
+		return NetworkMetrics{}, err
+	}
+	last, err := e.db.BlockMetrics(end, end)
+	if err != nil {
+		return NetworkMetrics{}, err
+	} else if len(first) == 0 || len(last) == 0 {
+		return NetworkMetrics{}, ErrNotFound
+	}
+
+	nm := NetworkMetrics{
+		Start:      first[0].Index,
+		End:        last[0].Index,
+		Difficulty: last[0].Difficulty,
+		TotalWork:  last[0].TotalWork,
+	}
+	if elapsed := last[0].Timestamp.Sub(first[0].Timestamp); end > start && elapsed > 0 {
+		nm.AverageBlockTime = elapsed / time.Duration(end-start)
+		if secs := uint64(elapsed / time.Second); secs > 0 {
+			nm.Hashrate = last[0].TotalWork.Sub(first[0].TotalWork).Div64(secs)
+		}
+	}
+	return nm, nil
+}
@@ -0,0 +1,97 @@
+package explorer
+
+import (
+	"go.sia.tech/core/types"
+)
+
+// A MinerPayout records the reward paid to the miner of a block. Subsidy is
+// the block reward, Fees is the sum of the miner fees of the block's
+// transactions, and Payout is the value of the miner payout output.
+type MinerPayout struct {
+	Index   types.ChainIndex
+	Address types.Address
+	Subsidy types.Currency
+	Fees    types.Currency
+	Payout  types.Currency
+}
+
+// EncodeTo implements types.EncoderTo.
+func (mp MinerPayout) EncodeTo(e *types.Encoder) {
+	mp.Index.EncodeTo(e)
+	mp.Address.EncodeTo(e)
+	mp.Subsidy.EncodeTo(e)
+	mp.Fees.EncodeTo(e)
+	mp.Payout.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (mp *MinerPayout) DecodeFrom(d *types.Decoder) {
+	mp.Index.DecodeFrom(d)
+	mp.Address.DecodeFrom(d)
+	mp.Subsidy.DecodeFrom(d)
+	mp.Fees.DecodeFrom(d)
+	mp.Payout.DecodeFrom(d)
+}
+
+// A MinerShare is the number of blocks mined by an address within a range of
+// heights, and the fraction of the range's blocks that represents.
+type MinerShare struct {
+	Address types.Address
+	Blocks  uint64
+	Share   float64
+}
+
+// minerPayout returns the MinerPayout for b. subsidy is the block reward of
+// the state b was applied to, and newSC are the siacoin elements created by b.
+func minerPayout(b types.Block, subsidy types.Currency, newSC []types.SiacoinElement) MinerPayout {
+	mp := MinerPayout{
+		Index:   b.Index(),
+		Address: b.Header.MinerAddress,
+		Subsidy: subsidy,
+	}
+	for _, txn := range b.Transactions {
+		mp.Fees = mp.Fees.Add(txn.MinerFee)
+	}
+	minerOutputID := b.MinerOutputID()
+	for _, sce := range newSC {
+		if sce.ID == minerOutputID {
+			mp.Payout = sce.Value
+			break
+		}
+	}
+	return mp
+}
+
+// MinerPayout returns the miner payout of the block at the specified height.
+func (e *Explorer) MinerPayout(height uint64) (MinerPayout, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.MinerPayout(height)
+}
+
+// MinerBlocks returns up to limit payouts of blocks mined by the specified
+// address, newest first, starting at cursor.
+func (e *Explorer) MinerBlocks(address types.Address, cursor Cursor, limit int) ([]MinerPayout, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.MinerBlocks(address, cursor, limit)
+}
+
+// MinerShares returns the share of blocks mined by each address at heights in
+// [start, end], largest first.
+func (e *Explorer) MinerShares(start, end uint64) ([]MinerShare, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	counts, err := e.db.MinerBlockCounts(start, end)
+	if err != nil {
+		return nil, err
+	}
+	var total uint64
+	for _, ms := range counts {
+		total += ms.Blocks
+	}
+	for i := range counts {
+		counts[i].Share = float64(counts[i].Blocks) / float64(total)
+	}
+	return counts, nil
+}
@@ -0,0 +1,79 @@
+package explorer
+
+import (
+	"go.sia.tech/core/types"
+)
+
+// A RankedBalance is an entry in a rich list. Addresses are ranked by
+// balance, largest first, with ties broken by address.
+type RankedBalance struct {
+	Rank     uint64
+	Address  types.Address
+	Siacoins types.Currency
+	Siafunds uint64
+}
+
+// An AddressRank is the position of an address in the siacoin and siafund
+// rich lists, and the number of addresses in each. A rank of zero means the
+// address holds none of that currency.
+type AddressRank struct {
+	SiacoinRank    uint64
+	SiacoinHolders uint64
+	SiafundRank    uint64
+	SiafundHolders uint64
+}
+
+// percentile returns the percentage of holders ranked at or below rank.
+func percentile(rank, holders uint64) float64 {
+	if rank == 0 || holders == 0 {
+		return 0
+	}
+	return 100 * float64(holders-rank+1) / float64(holders)
+}
+
+// SiacoinPercentile returns the percentage of siacoin holders whose balance
+// does not exceed that of the address, or 0 if it holds no siacoins.
+func (ar AddressRank) SiacoinPercentile() float64 {
+	return percentile(ar.SiacoinRank, ar.SiacoinHolders)
+}
+
+// SiafundPercentile returns the percentage of siafund holders whose balance
+// does not exceed that of the address, or 0 if it holds no siafunds.
+func (ar AddressRank) SiafundPercentile() float64 {
+	return percentile(ar.SiafundRank, ar.SiafundHolders)
+}
+
+// SiacoinRichList returns up to limit addresses ranked by siacoin balance,
+// starting at cursor.
+func (e *Explorer) SiacoinRichList(cursor Cursor, limit int) ([]RankedBalance, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.SiacoinRichList(cursor, limit)
+}
+
+// SiafundRichList returns up to limit addresses ranked by siafund balance,
+// starting at cursor.
+func (e *Explorer) SiafundRichList(cursor Cursor, limit int) ([]RankedBalance, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.SiafundRichList(cursor, limit)
+}
+
+// AddressRank returns the rank of an address in the siacoin and siafund rich
+// lists. The number of holders is maintained as blocks are processed, but
+// ranks are computed on demand by counting the addresses ranked above the
+// address, so the cost of a lookup grows with its rank.
+func (e *Explorer) AddressRank(address types.Address) (AddressRank, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	scRank, sfRank, err := e.db.AddressRank(address)
+	if err != nil {
+		return AddressRank{}, err
+	}
+	return AddressRank{
+		SiacoinRank:    scRank,
+		SiacoinHolders: e.tipStats.SiacoinHolders,
+		SiafundRank:    sfRank,
+		SiafundHolders: e.tipStats.SiafundHolders,
+	}, nil
+}
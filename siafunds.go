@@ -0,0 +1,173 @@
+package explorer
+
+import (
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+)
+
+// A SiafundClaim records the siacoins claimed from the siafund pool when a
+// siafund element was spent. Address is the address of the spent element;
+// the claim is paid to ClaimAddress. PoolValue is the value of the siafund
+// pool when the claim was made.
+type SiafundClaim struct {
+	Element      types.ElementID
+	Index        types.ChainIndex
+	Transaction  types.TransactionID
+	Address      types.Address
+	ClaimAddress types.Address
+	Siafunds     uint64
+	ClaimStart   types.Currency
+	PoolValue    types.Currency
+	Claim        types.Currency
+}
+
+// EncodeTo implements types.EncoderTo.
+func (sc SiafundClaim) EncodeTo(e *types.Encoder) {
+	sc.Element.EncodeTo(e)
+	sc.Index.EncodeTo(e)
+	sc.Transaction.EncodeTo(e)
+	sc.Address.EncodeTo(e)
+	sc.ClaimAddress.EncodeTo(e)
+	e.WriteUint64(sc.Siafunds)
+	sc.ClaimStart.EncodeTo(e)
+	sc.PoolValue.EncodeTo(e)
+	sc.Claim.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (sc *SiafundClaim) DecodeFrom(d *types.Decoder) {
+	sc.Element.DecodeFrom(d)
+	sc.Index.DecodeFrom(d)
+	sc.Transaction.DecodeFrom(d)
+	sc.Address.DecodeFrom(d)
+	sc.ClaimAddress.DecodeFrom(d)
+	sc.Siafunds = d.ReadUint64()
+	sc.ClaimStart.DecodeFrom(d)
+	sc.PoolValue.DecodeFrom(d)
+	sc.Claim.DecodeFrom(d)
+}
+
+// SiafundPoolGrowth records the value of the siafund pool after a block, and
+// the amount the block added to it.
+type SiafundPoolGrowth struct {
+	Index  types.ChainIndex
+	Pool   types.Currency
+	Growth types.Currency
+}
+
+// EncodeTo implements types.EncoderTo.
+func (pg SiafundPoolGrowth) EncodeTo(e *types.Encoder) {
+	pg.Index.EncodeTo(e)
+	pg.Pool.EncodeTo(e)
+	pg.Growth.EncodeTo(e)
+}
+
+// DecodeFrom implements types.DecoderFrom.
+func (pg *SiafundPoolGrowth) DecodeFrom(d *types.Decoder) {
+	pg.Index.DecodeFrom(d)
+	pg.Pool.DecodeFrom(d)
+	pg.Growth.DecodeFrom(d)
+}
+
+// SiafundRevenue summarizes the siafund pool revenue of an address. Accrued is
+// the amount its unspent siafund elements could currently claim, and Claimed
+// is the total claimed by siafund elements it has spent.
+type SiafundRevenue struct {
+	Siafunds uint64
+	Accrued  types.Currency
+	Claimed  types.Currency
+}
+
+// claimValue returns the siacoins that sfe would claim if it were spent in a
+// block applied to s, mirroring the claim outputs created by consensus.
+func claimValue(s consensus.State, sfe types.SiafundElement) types.Currency {
+	return s.SiafundPool.Sub(sfe.ClaimStart).Div64(s.SiafundCount()).Mul64(sfe.Value)
+}
+
+// siafundClaims returns the siafund claims made by b, which was applied to
+// the state s.
+func siafundClaims(s consensus.State, b types.Block) []SiafundClaim {
+	index := b.Index()
+	var claims []SiafundClaim
+	for _, txn := range b.Transactions {
+		txid := txn.ID()
+		for _, in := range txn.SiafundInputs {
+			claims = append(claims, SiafundClaim{
+				Element:      in.Parent.ID,
+				Index:        index,
+				Transaction:  txid,
+				Address:      in.Parent.Address,
+				ClaimAddress: in.ClaimAddress,
+				Siafunds:     in.Parent.Value,
+				ClaimStart:   in.Parent.ClaimStart,
+				PoolValue:    s.SiafundPool,
+				Claim:        claimValue(s, in.Parent),
+			})
+		}
+	}
+	return claims
+}
+
+// SiafundClaim returns the claim made when the siafund element with the
+// specified ID was spent.
+func (e *Explorer) SiafundClaim(id types.ElementID) (SiafundClaim, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.SiafundClaim(id)
+}
+
+// SiafundClaims returns up to limit claims made by siafund elements spent by
+// the specified address, newest first, starting at cursor.
+func (e *Explorer) SiafundClaims(address types.Address, cursor Cursor, limit int) ([]SiafundClaim, Cursor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.SiafundClaims(address, cursor, limit)
+}
+
+// AccruedClaim returns the siacoins that the specified unspent siafund element
+// could currently claim.
+func (e *Explorer) AccruedClaim(sfe types.SiafundElement) types.Currency {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return claimValue(e.cs, sfe)
+}
+
+// SiafundRevenue returns the siafund pool revenue of an address.
+func (e *Explorer) SiafundRevenue(address types.Address) (SiafundRevenue, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var sr SiafundRevenue
+	var err error
+	if _, sr.Siafunds, err = e.db.AddressBalance(address); err != nil {
+		return SiafundRevenue{}, err
+	} else if sr.Claimed, err = e.db.ClaimedSiacoins(address); err != nil {
+		return SiafundRevenue{}, err
+	}
+
+	var cursor Cursor
+	for {
+		ids, next, err := e.db.UnspentSiafundElements(address, cursor, 1000)
+		if err != nil {
+			return SiafundRevenue{}, err
+		}
+		for _, id := range ids {
+			sfe, err := e.db.SiafundElement(id)
+			if err != nil {
+				return SiafundRevenue{}, err
+			}
+			sr.Accrued = sr.Accrued.Add(claimValue(e.cs, sfe))
+		}
+		if next == "" {
+			return sr, nil
+		}
+		cursor = next
+	}
+}
+
+// SiafundPoolHistory returns the value and growth of the siafund pool after
+// each block in the best chain at heights in [start, end].
+func (e *Explorer) SiafundPoolHistory(start, end uint64) ([]SiafundPoolGrowth, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.db.SiafundPoolHistory(start, end)
+}
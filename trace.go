@@ -0,0 +1,125 @@
+package explorer
+
+import (
+	"errors"
+
+	"go.sia.tech/core/types"
+)
+
+// A TraceStep is a transaction encountered while walking the graph of inputs
+// and outputs, together with the elements it consumed and created.
+type TraceStep struct {
+	Transaction types.TransactionID
+	Height      uint64
+	Depth       int
+	Inputs      []types.ElementID
+	Outputs     []types.ElementID
+}
+
+// transactionInputs returns the IDs of the elements consumed by txn.
+func transactionInputs(txn types.Transaction) []types.ElementID {
+	var ids []types.ElementID
+	for _, in := range txn.SiacoinInputs {
+		ids = append(ids, in.Parent.ID)
+	}
+	for _, in := range txn.SiafundInputs {
+		ids = append(ids, in.Parent.ID)
+	}
+	for _, fcr := range txn.FileContractResolutions {
+		ids = append(ids, fcr.Parent.ID)
+	}
+	return ids
+}
+
+// transactionOutputs returns the IDs of the elements created by txn, in the
+// same order in which consensus assigns them.
+func transactionOutputs(txn types.Transaction) []types.ElementID {
+	txid := txn.ID()
+	var ids []types.ElementID
+	nextID := func() {
+		ids = append(ids, types.ElementID{
+			Source: types.Hash256(txid),
+			Index:  uint64(len(ids)),
+		})
+	}
+	for range txn.SiacoinOutputs {
+		nextID()
+	}
+	for range txn.SiafundInputs {
+		nextID() // siafund claim output
+	}
+	for range txn.SiafundOutputs {
+		nextID()
+	}
+	for range txn.FileContracts {
+		nextID()
+	}
+	for _, fcr := range txn.FileContractResolutions {
+		if fcr.HasRenewal() {
+			nextID() // renewed contract
+		}
+		nextID() // renter output
+		nextID() // host output
+	}
+	return ids
+}
+
+// Trace walks the transaction graph starting at the element with the given
+// ID, returning each transaction encountered up to maxDepth hops away. If
+// forward is true, the walk follows elements to the transactions that spent
+// them; otherwise, it follows elements back to the transactions that created
+// them. Elements not created by a transaction, such as block rewards, end a
+// backward walk.
+func (e *Explorer) Trace(id types.ElementID, forward bool, maxDepth int) ([]TraceStep, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var steps []TraceStep
+	seen := make(map[types.TransactionID]bool)
+	frontier := []types.ElementID{id}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []types.ElementID
+		for _, id := range frontier {
+			var txid types.TransactionID
+			if forward {
+				status, err := e.db.ElementStatus(id)
+				if err != nil {
+					return nil, err
+				} else if !status.Spent {
+					continue
+				}
+				txid = status.SpentBy
+			} else {
+				txid = types.TransactionID(id.Source)
+			}
+			if seen[txid] {
+				continue
+			}
+			txn, err := e.db.Transaction(txid)
+			if errors.Is(err, ErrNotFound) && !forward {
+				continue // not created by a transaction
+			} else if err != nil {
+				return nil, err
+			}
+			meta, err := e.db.TransactionMetadata(txid)
+			if err != nil {
+				return nil, err
+			}
+			seen[txid] = true
+			step := TraceStep{
+				Transaction: txid,
+				Height:      meta.Block.Height,
+				Depth:       depth,
+				Inputs:      transactionInputs(txn),
+				Outputs:     transactionOutputs(txn),
+			}
+			steps = append(steps, step)
+			if forward {
+				next = append(next, step.Outputs...)
+			} else {
+				next = append(next, step.Inputs...)
+			}
+		}
+		frontier = next
+	}
+	return steps, nil
+}
@@ -0,0 +1,439 @@
+package explorer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"go.sia.tech/core/types"
+)
+
+// WebhookSignatureHeader is the HTTP header carrying the HMAC-SHA256 signature
+// of a webhook delivery, computed over the request body with the webhook's
+// secret and encoded as "sha256=<hex>".
+const WebhookSignatureHeader = "X-Explorer-Signature"
+
+// ErrInvalidWebhook is returned when registering a malformed webhook.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// A Webhook notifies a URL of transactions involving a set of watched
+// addresses and contracts.
+type Webhook struct {
+	ID        int64             `json:"id"`
+	URL       string            `json:"url"`
+	Secret    string            `json:"secret,omitempty"`
+	Addresses []types.Address   `json:"addresses"`
+	Contracts []types.ElementID `json:"contracts"`
+}
+
+// A WebhookNotification is the body of a webhook delivery. If Reverted is
+// true, the block containing the transaction was reorged out, and any previous
+// notification for it should be disregarded. Transaction is zero for payments
+// made outside of any transaction, i.e. miner payouts and Foundation
+// subsidies; Elements lists the siacoin elements they created.
+type WebhookNotification struct {
+	Webhook     int64               `json:"webhook"`
+	Reverted    bool                `json:"reverted"`
+	Index       types.ChainIndex    `json:"index"`
+	Transaction types.TransactionID `json:"transaction"`
+	Addresses   []types.Address     `json:"addresses,omitempty"`
+	Contracts   []types.ElementID   `json:"contracts,omitempty"`
+	Elements    []types.ElementID   `json:"elements,omitempty"`
+}
+
+// A WebhookDelivery is a notification awaiting delivery to a webhook.
+// Attempts is the number of failed attempts made so far.
+type WebhookDelivery struct {
+	ID       int64
+	Webhook  int64
+	Body     []byte
+	Attempts int
+}
+
+// A WebhookStore persists webhooks and their pending deliveries. Removing a
+// webhook also removes its pending deliveries.
+type WebhookStore interface {
+	AddWebhook(wh Webhook) (int64, error)
+	RemoveWebhook(id int64) error
+	Webhooks() ([]Webhook, error)
+
+	AddDelivery(d WebhookDelivery) (int64, error)
+	UpdateDelivery(id int64, attempts int) error
+	RemoveDelivery(id int64) error
+	Deliveries() ([]WebhookDelivery, error)
+}
+
+// SignWebhook returns the signature of a webhook delivery body.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// A webhookQueue holds the pending deliveries of a single webhook, oldest
+// first. Each webhook is served by its own goroutine, so that a slow receiver
+// only delays its own notifications, which are delivered in order.
+type webhookQueue struct {
+	deliveries []WebhookDelivery
+	wake       chan struct{}
+	removed    chan struct{}
+}
+
+// A WebhookOption configures a WebhookManager.
+type WebhookOption func(*WebhookManager)
+
+// WithRetryBackoff sets the delay before the first retry of a failed
+// delivery; it doubles with each subsequent attempt. The default is 5 seconds.
+func WithRetryBackoff(d time.Duration) WebhookOption {
+	return func(m *WebhookManager) { m.retryBackoff = d }
+}
+
+// WithMaxAttempts sets the number of attempts after which a delivery is
+// abandoned. The default is 10.
+func WithMaxAttempts(n int) WebhookOption {
+	return func(m *WebhookManager) { m.maxAttempts = n }
+}
+
+// WithLogger sets the logger that store errors are reported to. The default
+// is the standard logger.
+func WithLogger(l *log.Logger) WebhookOption {
+	return func(m *WebhookManager) { m.log = l }
+}
+
+// A WebhookManager delivers notifications to registered webhooks. It is an
+// EventSubscriber, and should be added to an Explorer. Pending deliveries are
+// persisted, and resumed by the next WebhookManager using the same store.
+type WebhookManager struct {
+	retryBackoff time.Duration
+	maxAttempts  int
+	log          *log.Logger
+
+	store  WebhookStore
+	client *http.Client
+
+	// events buffers published events until they are matched against the
+	// registered webhooks and persisted, so that ProcessEvent need not
+	// wait on the store
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.Mutex
+	hooks  map[int64]Webhook
+	queues map[int64]*webhookQueue
+	closed chan struct{}
+}
+
+// match returns a notification for each webhook watching an address or
+// contract involved in ev.
+func (m *WebhookManager) match(ev Event) []WebhookNotification {
+	var notifications []WebhookNotification
+	for _, wh := range m.hooks {
+		n := WebhookNotification{
+			Webhook:     wh.ID,
+			Reverted:    ev.Type == EventTransactionReverted || ev.Type == EventBlockReverted,
+			Index:       ev.Index,
+			Transaction: ev.Transaction,
+		}
+		for _, addr := range wh.Addresses {
+			for _, a := range ev.Addresses {
+				if a == addr {
+					n.Addresses = append(n.Addresses, addr)
+					break
+				}
+			}
+		}
+		for _, id := range wh.Contracts {
+			for _, e := range ev.Elements {
+				if e == id {
+					n.Contracts = append(n.Contracts, id)
+					break
+				}
+			}
+		}
+		if ev.Type == EventBlockApplied || ev.Type == EventBlockReverted {
+			// block events only carry payouts; contracts can't be involved
+			if len(n.Addresses) == 0 {
+				continue
+			}
+			n.Contracts = nil
+			n.Elements = ev.Elements
+		}
+		if len(n.Addresses) != 0 || len(n.Contracts) != 0 {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications
+}
+
+// enqueue persists a delivery and hands it to the webhook's queue.
+func (m *WebhookManager) enqueue(d WebhookDelivery) {
+	id, err := m.store.AddDelivery(d)
+	if err != nil {
+		m.log.Printf("webhooks: failed to persist delivery to webhook %v: %v", d.Webhook, err)
+		return
+	}
+	d.ID = id
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q, ok := m.queues[d.Webhook]
+	if !ok {
+		// the webhook was removed while the delivery was being persisted
+		if err := m.store.RemoveDelivery(d.ID); err != nil {
+			m.log.Printf("webhooks: failed to remove delivery %v: %v", d.ID, err)
+		}
+		return
+	}
+	q.deliveries = append(q.deliveries, d)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// persist matches published events against the registered webhooks and
+// enqueues the resulting deliveries. When the manager is closed, it drains
+// the events already buffered before returning.
+func (m *WebhookManager) persist() {
+	defer close(m.done)
+	handle := func(ev Event) {
+		m.mu.Lock()
+		notifications := m.match(ev)
+		m.mu.Unlock()
+		for _, n := range notifications {
+			body, _ := json.Marshal(n)
+			m.enqueue(WebhookDelivery{Webhook: n.Webhook, Body: body})
+		}
+	}
+	for {
+		select {
+		case ev := <-m.events:
+			handle(ev)
+		case <-m.closed:
+			for {
+				select {
+				case ev := <-m.events:
+					handle(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver attempts to deliver d, returning false if it should be retried.
+func (m *WebhookManager) deliver(d WebhookDelivery) bool {
+	m.mu.Lock()
+	wh, ok := m.hooks[d.Webhook]
+	m.mu.Unlock()
+	if !ok {
+		return true // webhook was removed
+	}
+	req, err := http.NewRequest("POST", wh.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return true // invalid URL; retrying won't help
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(wh.Secret, d.Body))
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// run delivers the queued notifications of a webhook, in order, until the
+// webhook is removed or the manager is closed. A failed delivery is retried
+// before any later delivery is attempted.
+func (m *WebhookManager) run(q *webhookQueue) {
+	for {
+		m.mu.Lock()
+		if len(q.deliveries) == 0 {
+			m.mu.Unlock()
+			select {
+			case <-q.wake:
+				continue
+			case <-q.removed:
+				return
+			case <-m.closed:
+				return
+			}
+		}
+		d := q.deliveries[0]
+		m.mu.Unlock()
+
+		if m.deliver(d) || d.Attempts+1 >= m.maxAttempts {
+			if err := m.store.RemoveDelivery(d.ID); err != nil {
+				m.log.Printf("webhooks: failed to remove delivery %v: %v", d.ID, err)
+			}
+			m.mu.Lock()
+			q.deliveries = q.deliveries[1:]
+			m.mu.Unlock()
+			continue
+		}
+		d.Attempts++
+		if err := m.store.UpdateDelivery(d.ID, d.Attempts); err != nil {
+			m.log.Printf("webhooks: failed to update delivery %v: %v", d.ID, err)
+		}
+		m.mu.Lock()
+		q.deliveries[0].Attempts = d.Attempts
+		m.mu.Unlock()
+		select {
+		case <-time.After(m.retryBackoff << (d.Attempts - 1)):
+		case <-q.removed:
+			return
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// addQueue creates the delivery queue of a webhook and starts serving it. It
+// must be called with m.mu held.
+func (m *WebhookManager) addQueue(id int64) *webhookQueue {
+	q := &webhookQueue{
+		wake:    make(chan struct{}, 1),
+		removed: make(chan struct{}),
+	}
+	m.queues[id] = q
+	go m.run(q)
+	return q
+}
+
+// ProcessEvent implements EventSubscriber. Events are buffered and persisted
+// in the background; ProcessEvent only waits if the buffer is full.
+func (m *WebhookManager) ProcessEvent(ev Event) {
+	switch ev.Type {
+	case EventTransactionApplied, EventTransactionReverted, EventBlockApplied, EventBlockReverted:
+	default:
+		return
+	}
+	select {
+	case m.events <- ev:
+	case <-m.closed:
+	}
+}
+
+// AddWebhook registers a webhook, returning it with its ID set. If wh.Secret
+// is empty, a random secret is generated.
+func (m *WebhookManager) AddWebhook(wh Webhook) (Webhook, error) {
+	if u, err := url.Parse(wh.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return Webhook{}, fmt.Errorf("%w: URL must be an absolute http(s) URL", ErrInvalidWebhook)
+	} else if len(wh.Addresses) == 0 && len(wh.Contracts) == 0 {
+		return Webhook{}, fmt.Errorf("%w: must watch at least one address or contract", ErrInvalidWebhook)
+	}
+	if wh.Secret == "" {
+		var buf [32]byte
+		if _, err := rand.Read(buf[:]); err != nil {
+			return Webhook{}, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		wh.Secret = hex.EncodeToString(buf[:])
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, err := m.store.AddWebhook(wh)
+	if err != nil {
+		return Webhook{}, err
+	}
+	wh.ID = id
+	m.hooks[id] = wh
+	m.addQueue(id)
+	return wh, nil
+}
+
+// RemoveWebhook unregisters a webhook. Pending deliveries to it are dropped.
+func (m *WebhookManager) RemoveWebhook(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.hooks[id]; !ok {
+		return ErrNotFound
+	} else if err := m.store.RemoveWebhook(id); err != nil {
+		return err
+	}
+	delete(m.hooks, id)
+	close(m.queues[id].removed)
+	delete(m.queues, id)
+	return nil
+}
+
+// Webhooks returns all registered webhooks, without their secrets.
+func (m *WebhookManager) Webhooks() []Webhook {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hooks := make([]Webhook, 0, len(m.hooks))
+	for _, wh := range m.hooks {
+		wh.Secret = ""
+		hooks = append(hooks, wh)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks
+}
+
+// Close stops delivering notifications. Events already buffered are
+// persisted before Close returns, and delivered by the next WebhookManager
+// using the same store.
+func (m *WebhookManager) Close() error {
+	close(m.closed)
+	<-m.done
+	return nil
+}
+
+// NewWebhookManager returns a WebhookManager for the webhooks in store.
+func NewWebhookManager(store WebhookStore, opts ...WebhookOption) (*WebhookManager, error) {
+	hooks, err := store.Webhooks()
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := store.Deliveries()
+	if err != nil {
+		return nil, err
+	}
+	m := &WebhookManager{
+		retryBackoff: 5 * time.Second,
+		maxAttempts:  10,
+		log:          log.Default(),
+
+		store:  store,
+		client: &http.Client{Timeout: 30 * time.Second},
+		events: make(chan Event, 1024),
+		done:   make(chan struct{}),
+		hooks:  make(map[int64]Webhook),
+		queues: make(map[int64]*webhookQueue),
+		closed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	go m.persist()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queues := make(map[int64]*webhookQueue)
+	for _, wh := range hooks {
+		m.hooks[wh.ID] = wh
+		queues[wh.ID] = m.addQueue(wh.ID)
+	}
+	// resume the deliveries left pending by a previous manager
+	for _, d := range deliveries {
+		if q, ok := queues[d.Webhook]; ok {
+			q.deliveries = append(q.deliveries, d)
+			select {
+			case q.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+	return m, nil
+}